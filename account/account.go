@@ -0,0 +1,144 @@
+// Package account holds the credentials that guard access to characters.
+// Passwords are only ever kept as bcrypt hashes, and an account is locked
+// for a while after repeated failed logins.
+package account
+
+import (
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+const (
+	MaxFailures    = 3
+	LockoutPeriod  = 5 * time.Minute
+	MinPasswordLen = 6
+)
+
+var (
+	AccountExists      = errors.New("account already exists")
+	AccountLocked      = errors.New("account is locked")
+	InvalidCredentials = errors.New("invalid name or password")
+	PasswordTooShort   = errors.New("password too short")
+)
+
+type Account struct {
+	Name         string
+	PasswordHash string
+
+	failures    int
+	lockedUntil time.Time
+}
+
+func (a *Account) IsLocked(now time.Time) bool {
+	return now.Before(a.lockedUntil)
+}
+
+type Accounts struct {
+	accounts map[string]*Account
+	now      func() time.Time
+}
+
+func New() *Accounts {
+	return &Accounts{
+		accounts: make(map[string]*Account),
+		now:      time.Now,
+	}
+}
+
+// Add registers an account with an already hashed password, as loaded from
+// disk.
+func (a *Accounts) Add(name string, passwordHash string) *Account {
+	account := &Account{Name: name, PasswordHash: passwordHash}
+	a.accounts[name] = account
+	return account
+}
+
+func (a *Accounts) Remove(name string) {
+	delete(a.accounts, name)
+}
+
+func (a *Accounts) Exists(name string) bool {
+	_, ok := a.accounts[name]
+	return ok
+}
+
+func (a *Accounts) All() []*Account {
+	all := make([]*Account, 0, len(a.accounts))
+	for _, account := range a.accounts {
+		all = append(all, account)
+	}
+	return all
+}
+
+// HashPassword hashes a new password.  Hashing is slow by design, and
+// unlike Accounts it's safe to call from any goroutine.
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLen {
+		return "", PasswordTooShort
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword compares a password with a hash.  Like HashPassword it's
+// slow and safe to call from any goroutine.
+func CheckPassword(hash string, password string) error {
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return InvalidCredentials
+	}
+	return nil
+}
+
+func (a *Accounts) Create(name string, password string) (*Account, error) {
+	if a.Exists(name) {
+		return nil, AccountExists
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	return a.Add(name, hash), nil
+}
+
+// PasswordHash returns the hash to check a login against, unless the
+// account is locked.
+func (a *Accounts) PasswordHash(name string) (string, error) {
+	account, ok := a.accounts[name]
+	if !ok {
+		return "", InvalidCredentials
+	}
+	if account.IsLocked(a.now()) {
+		return "", AccountLocked
+	}
+	return account.PasswordHash, nil
+}
+
+// Record counts the result of checking a password toward the lockout.
+func (a *Accounts) Record(name string, checked error) error {
+	account, ok := a.accounts[name]
+	if !ok {
+		return InvalidCredentials
+	}
+	if checked != nil {
+		account.failures++
+		if account.failures >= MaxFailures {
+			account.failures = 0
+			account.lockedUntil = a.now().Add(LockoutPeriod)
+		}
+		return InvalidCredentials
+	}
+	account.failures = 0
+	return nil
+}
+
+func (a *Accounts) Authenticate(name string, password string) error {
+	hash, err := a.PasswordHash(name)
+	if err != nil {
+		return err
+	}
+	return a.Record(name, CheckPassword(hash, password))
+}
@@ -0,0 +1,50 @@
+package account
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccount(t *testing.T) {
+	accounts := New()
+	if _, err := accounts.Create("tester", "short"); err != PasswordTooShort {
+		t.Fatalf("Created account with short password")
+	}
+	account, err := accounts.Create("tester", "password")
+	if err != nil {
+		t.Fatalf("Unable to create account %v", err)
+	}
+	if account.PasswordHash == "password" {
+		t.Fatalf("Password stored in plain text")
+	}
+	if _, err := accounts.Create("tester", "password"); err != AccountExists {
+		t.Fatalf("Created duplicate account")
+	}
+	if err := accounts.Authenticate("tester", "password"); err != nil {
+		t.Fatalf("Unable to authenticate %v", err)
+	}
+	if err := accounts.Authenticate("tester", "wrong"); err != InvalidCredentials {
+		t.Fatalf("Authenticated with wrong password")
+	}
+	if err := accounts.Authenticate("nobody", "password"); err != InvalidCredentials {
+		t.Fatalf("Authenticated missing account")
+	}
+}
+
+func TestAccountLockout(t *testing.T) {
+	accounts := New()
+	now := time.Now()
+	accounts.now = func() time.Time { return now }
+	accounts.Create("tester", "password")
+
+	for i := 0; i < MaxFailures; i++ {
+		accounts.Authenticate("tester", "wrong")
+	}
+	if err := accounts.Authenticate("tester", "password"); err != AccountLocked {
+		t.Fatalf("Account not locked after %d failures", MaxFailures)
+	}
+	now = now.Add(LockoutPeriod)
+	if err := accounts.Authenticate("tester", "password"); err != nil {
+		t.Fatalf("Account still locked after lockout period %v", err)
+	}
+}
@@ -12,6 +12,18 @@ func (e EssenceOnDeath) Handle(World *world.World, payload events.CharacterDeath
 	payload.Character.DebitEssence(amount)
 	payload.Character.Showln("%d essence flows from you.", amount)
 	killer := payload.Killer
-	killer.Showln("%d essence flows to you.", amount)
-	killer.CreditEssence(amount)
+	if killer == nil {
+		return
+	}
+	// Group members in the room split the essence, the killer keeps the rest
+	members := killer.GroupInRoom()
+	share := amount / len(members)
+	for _, member := range members {
+		credit := share
+		if member == killer {
+			credit += amount % len(members)
+		}
+		member.Showln("%d essence flows to you.", credit)
+		member.CreditEssence(credit)
+	}
 }
@@ -0,0 +1,26 @@
+package actions
+
+import (
+	"github.com/michaelvmata/path/events"
+	"github.com/michaelvmata/path/world"
+	"testing"
+)
+
+func TestEssenceOnDeath_Group(t *testing.T) {
+	room := world.NewRoom("Room UUID", "Test Room", "", 10, nil)
+	killer := world.NewPlayer("Killer UUID", "Killer")
+	member := world.NewPlayer("Member UUID", "Member")
+	away := world.NewPlayer("Away UUID", "Away")
+	victim := world.NewPlayer("Victim UUID", "Victim")
+	killer.Room = room
+	member.Room = room
+	group := world.NewGroup(killer)
+	group.Add(member)
+	group.Add(away)
+	victim.Essence = 11
+
+	EssenceOnDeath{}.Handle(nil, events.CharacterDeathPayload{Character: victim, Killer: killer})
+	if killer.Essence != 6 || member.Essence != 5 || away.Essence != 0 {
+		t.Fatalf("Essence split killer(%d) member(%d) away(%d)", killer.Essence, member.Essence, away.Essence)
+	}
+}
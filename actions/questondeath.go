@@ -12,20 +12,26 @@ type QuestOnDeath struct{}
 
 func (qod QuestOnDeath) Handle(World *world.World, payload events.CharacterDeathPayload) {
 	log.Printf("Considering quest on death")
-	for _, q := range payload.Killer.Quests {
-		for _, step := range q.Steps {
-			if killMobiles, ok := step.(*quest.KillMobiles); ok {
-				killMobiles.Increment(payload.Killer.UUID, payload.Character.UUID, 1)
+	if payload.Killer == nil {
+		return
+	}
+	// Every group member in the room gets credit for the kill
+	for _, member := range payload.Killer.GroupInRoom() {
+		for _, q := range member.Quests {
+			for _, step := range q.Steps {
+				if killMobiles, ok := step.(*quest.KillMobiles); ok {
+					killMobiles.Increment(member.UUID, payload.Character.UUID, 1)
+				}
+			}
+			if q.IsComplete() {
+				qod.AssignRewards(World, member, q)
 			}
-		}
-		if q.IsComplete() {
-			qod.AssignRewards(World, payload.Killer, q)
 		}
 	}
 }
 
-type GetItemer interface {
-	GetItem(string) (item.Item, bool)
+type ItemSpawner interface {
+	SpawnItem(string) (item.Item, bool)
 }
 
 type Player interface {
@@ -34,17 +40,19 @@ type Player interface {
 	Showln(string, ...interface{})
 }
 
-func (qod QuestOnDeath) AssignRewards(World GetItemer, player Player, q *quest.Quest) {
+func (qod QuestOnDeath) AssignRewards(World ItemSpawner, player Player, q *quest.Quest) {
 	if q.Reward.Essence > 0 {
 		player.Showln("You earned %d essence.", q.Reward.Essence)
 		player.AdjustEssence(q.Reward.Essence)
 	}
 	for _, i := range q.Reward.Items {
-		item, ok := World.GetItem(i.UUID)
-		if !ok {
-			log.Fatalf("Item reward not found %s for quest %s", i.UUID, q.UUID)
+		for n := 0; n < i.Count; n++ {
+			item, ok := World.SpawnItem(i.UUID)
+			if !ok {
+				log.Fatalf("Item reward not found %s for quest %s", i.UUID, q.UUID)
+			}
+			player.Receive(item)
+			player.Showln("You earned %s.", item.Name())
 		}
-		player.Receive(item)
-		player.Showln("You earned %s.", item.Name())
 	}
 }
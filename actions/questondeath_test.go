@@ -14,7 +14,7 @@ func (m MockPlayer) Showln(s string, args ...interface{}) {}
 
 type MockWorld struct{}
 
-func (m MockWorld) GetItem(uuid string) (item.Item, bool) {
+func (m MockWorld) SpawnItem(uuid string) (item.Item, bool) {
 	i := item.NewItem("Test Item UUID", "Name", []string{"Test Item"}, "Test Item", "Test Type")
 	return i, true
 }
@@ -20,6 +20,11 @@ func (rp RespawnCharacter) Handle(World *world.World, payload events.CharacterDe
 		message.FirstPersonMessage = fmt.Sprintf("Victory!  %s falls at your hand.", char.Name)
 		message.SecondPersonMessage = fmt.Sprintf("You were defeated by %s.", payload.Killer.Name)
 		message.ThirdPersonMessage = fmt.Sprintf("%s defeated %s", payload.Killer.Name, char.Name)
+		if payload.Killer.Room != char.Room {
+			// Killed from another room, like by a shot
+			payload.Killer.Showln(message.FirstPersonMessage)
+			message.FirstPerson = nil
+		}
 	} else {
 		message.SecondPersonMessage = "You succumb to your wounds."
 		message.ThirdPersonMessage = fmt.Sprintf("%s succumbs to their wounds.", char.Name)
@@ -28,7 +33,7 @@ func (rp RespawnCharacter) Handle(World *world.World, payload events.CharacterDe
 	message.SecondPerson.Room.ShowMessage(message)
 
 	if World.IsMobile(char) {
-		World.Mobiles.Unspawn(char)
+		World.UnspawnMobile(char)
 		if err := char.Room.Exit(char); err != nil {
 			log.Fatalf("Character died without exiting room %v", payload)
 		}
@@ -58,6 +63,8 @@ func (rp RespawnCharacter) Handle(World *world.World, payload events.CharacterDe
 	}
 	for _, opponent := range opponents {
 		opponent.StopAttacking(payload.Character)
+		opponent.Threat.Remove(payload.Character)
 		char.StopAttacking(opponent)
 	}
+	char.Threat = make(world.Threat)
 }
@@ -5,7 +5,7 @@ import "github.com/michaelvmata/path/world"
 var BarrierName = "barrier"
 
 type Barrier struct {
-	CoolDown
+	Effect
 	Level     int
 	Character *world.Character
 }
@@ -31,6 +31,7 @@ func (b Barrier) DamageReduction() int {
 }
 
 func NewBarrier(character *world.Character) *Barrier {
-	level := character.Skills.Barrier.Value()
-	return &Barrier{CoolDown: NewCoolDown(60, BarrierName), Level: level, Character: character}
+	level := character.Skills.Level(BarrierName)
+	stacking := world.Stacking{Rule: world.Refresh}
+	return &Barrier{Effect: NewEffect(60, BarrierName, stacking, world.Beneficial, world.Magic), Level: level, Character: character}
 }
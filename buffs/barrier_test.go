@@ -7,7 +7,7 @@ import (
 
 func TestBarrier(t *testing.T) {
 	character := world.NewPlayer("Test UUID", "Test Handle")
-	character.Skills.Barrier.Increment()
+	character.Skills.Get(BarrierName).Increment()
 	b := NewBarrier(character)
 	if b.DamageReduction() == 0 {
 		t.Fatalf("Barrier damage reduction is 0")
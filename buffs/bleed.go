@@ -1,14 +1,16 @@
 package buffs
 
 import (
+	"fmt"
 	"github.com/michaelvmata/path/world"
 )
 
 var BleedName = "bleed"
 
 type Bleed struct {
-	CoolDown
+	Effect
 	Level     int
+	Power     int
 	Character *world.Character
 	Applier   *world.Character
 }
@@ -22,14 +24,42 @@ func (b *Bleed) UnapplyMessage() string {
 }
 
 func (b *Bleed) AlreadyApplied() string {
-	return "Your gaping wound continues to bleed."
+	return "Your wounds bleed even more."
 }
 
 func (b *Bleed) Upkeep() int {
 	return b.Level + 1
 }
 
+// Damage grows with the applier's bleed level and power and with each
+// stack.
+func (b *Bleed) Damage() int {
+	return (b.Level + 1) * b.Power * b.Stacks()
+}
+
+func (b *Bleed) Tick() world.Tick {
+	amount := b.Damage()
+	return world.Tick{
+		Source: b.Applier,
+		Damage: amount,
+		Message: world.Message{
+			FirstPerson:         b.Applier,
+			FirstPersonMessage:  fmt.Sprintf("%s takes %d bleed damage from the wound you inflicted.", b.Character.Name, amount),
+			SecondPerson:        b.Character,
+			SecondPersonMessage: fmt.Sprintf("You take %d bleed damage.", amount),
+			ThirdPersonMessage:  fmt.Sprintf("%s takes %d bleed damage.", b.Character.Name, amount),
+		},
+	}
+}
+
 func NewBleed(character *world.Character, applier *world.Character) *Bleed {
-	level := character.Skills.Bleed.Value()
-	return &Bleed{CoolDown: NewCoolDown(6, BleedName), Level: level, Character: character, Applier: applier}
+	level := applier.Skills.Level(BleedName)
+	stacking := world.Stacking{Rule: world.Intensify, Maximum: 3}
+	return &Bleed{
+		Effect:    NewEffect(6, BleedName, stacking, world.Harmful, world.Physical),
+		Level:     level,
+		Power:     applier.Core.Power.Value(),
+		Character: character,
+		Applier:   applier,
+	}
 }
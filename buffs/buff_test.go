@@ -11,6 +11,9 @@ func TestAllBuffs(t *testing.T) {
 		NewBarrier(character),
 		NewBleed(character, character),
 		NewHaste(character),
+		NewRegenerate(character, character),
+		NewSiphon(character, character),
+		NewWeaken(character, character),
 	}
 	for _, buff := range buffs {
 		if buff.Name() == "" {
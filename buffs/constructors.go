@@ -0,0 +1,19 @@
+package buffs
+
+import "github.com/michaelvmata/path/world"
+
+// Constructors build the buffs techniques apply, by name.
+var Constructors = map[string]func(character *world.Character, applier *world.Character) world.Buff{
+	BleedName: func(character *world.Character, applier *world.Character) world.Buff {
+		return NewBleed(character, applier)
+	},
+	RegenerateName: func(character *world.Character, applier *world.Character) world.Buff {
+		return NewRegenerate(character, applier)
+	},
+	SiphonName: func(character *world.Character, applier *world.Character) world.Buff {
+		return NewSiphon(character, applier)
+	},
+	WeakenName: func(character *world.Character, applier *world.Character) world.Buff {
+		return NewWeaken(character, applier)
+	},
+}
@@ -0,0 +1,44 @@
+package buffs
+
+import "github.com/michaelvmata/path/world"
+
+// Effect is what every buff shares, its lifetime, how it stacks and the
+// categories that dispel it.
+type Effect struct {
+	CoolDown
+	Duration   int
+	stacking   world.Stacking
+	stacks     int
+	categories []world.Category
+}
+
+func (e *Effect) Stacking() world.Stacking {
+	return e.stacking
+}
+
+func (e *Effect) Stacks() int {
+	return e.stacks
+}
+
+func (e *Effect) Refresh() {
+	e.Lifetime = e.Duration
+}
+
+func (e *Effect) Intensify() {
+	e.stacks++
+	e.Refresh()
+}
+
+func (e *Effect) Categories() []world.Category {
+	return e.categories
+}
+
+func NewEffect(duration int, name string, stacking world.Stacking, categories ...world.Category) Effect {
+	return Effect{
+		CoolDown:   NewCoolDown(duration, name),
+		Duration:   duration,
+		stacking:   stacking,
+		stacks:     1,
+		categories: categories,
+	}
+}
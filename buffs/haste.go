@@ -5,7 +5,7 @@ import "github.com/michaelvmata/path/world"
 var HasteName = "haste"
 
 type Haste struct {
-	CoolDown
+	Effect
 	Level int
 }
 
@@ -30,6 +30,7 @@ func (h Haste) NumberOfAttacks() int {
 }
 
 func NewHaste(character *world.Character) *Haste {
-	level := character.Skills.Haste.Value()
-	return &Haste{CoolDown: NewCoolDown(60, HasteName), Level: level}
+	level := character.Skills.Level(HasteName)
+	stacking := world.Stacking{Rule: world.Refresh}
+	return &Haste{Effect: NewEffect(60, HasteName, stacking, world.Beneficial, world.Magic), Level: level}
 }
@@ -7,7 +7,7 @@ import (
 
 func TestHaste(t *testing.T) {
 	character := world.NewPlayer("Test UUID", "Test Handle")
-	character.Skills.Haste.Increment()
+	character.Skills.Get(HasteName).Increment()
 	h := NewHaste(character)
 	if h.NumberOfAttacks() <= 0 {
 		t.Fatalf("Invalid number of attacks")
@@ -0,0 +1,68 @@
+package buffs
+
+import (
+	"fmt"
+	"github.com/michaelvmata/path/world"
+)
+
+var RegenerateName = "regenerate"
+
+// Regenerate restores health and spirit every battle tick.
+type Regenerate struct {
+	Effect
+	Level     int
+	Will      int
+	Character *world.Character
+	Applier   *world.Character
+}
+
+func (r *Regenerate) ApplyMessage() string {
+	return "Warmth spreads through your body."
+}
+
+func (r *Regenerate) UnapplyMessage() string {
+	return "The warmth in your body fades."
+}
+
+func (r *Regenerate) AlreadyApplied() string {
+	return "The warmth in your body renews."
+}
+
+func (r *Regenerate) Upkeep() int {
+	return r.Level + 1
+}
+
+// Heal grows with the applier's regenerate level and will.
+func (r *Regenerate) Heal() int {
+	return (r.Level + 1) * r.Will
+}
+
+func (r *Regenerate) Spirit() int {
+	return r.Will
+}
+
+func (r *Regenerate) Tick() world.Tick {
+	return world.Tick{
+		Source: r.Applier,
+		Heal:   r.Heal(),
+		Spirit: r.Spirit(),
+		Healed: func(amount int) world.Message {
+			return world.Message{
+				SecondPerson:        r.Character,
+				SecondPersonMessage: fmt.Sprintf("You regenerate %d health.", amount),
+			}
+		},
+	}
+}
+
+func NewRegenerate(character *world.Character, applier *world.Character) *Regenerate {
+	level := applier.Skills.Level(RegenerateName)
+	stacking := world.Stacking{Rule: world.Refresh}
+	return &Regenerate{
+		Effect:    NewEffect(30, RegenerateName, stacking, world.Beneficial, world.Magic),
+		Level:     level,
+		Will:      applier.Core.Will.Value(),
+		Character: character,
+		Applier:   applier,
+	}
+}
@@ -0,0 +1,66 @@
+package buffs
+
+import (
+	"fmt"
+	"github.com/michaelvmata/path/world"
+)
+
+var SiphonName = "siphon"
+
+// Siphon drains spirit every battle tick and gives it to the applier.
+type Siphon struct {
+	Effect
+	Level     int
+	Insight   int
+	Character *world.Character
+	Applier   *world.Character
+}
+
+func (s *Siphon) ApplyMessage() string {
+	return "Your spirit begins to seep away."
+}
+
+func (s *Siphon) UnapplyMessage() string {
+	return "Your spirit stops seeping away."
+}
+
+func (s *Siphon) AlreadyApplied() string {
+	return "Your spirit continues to seep away."
+}
+
+func (s *Siphon) Upkeep() int {
+	return s.Level + 1
+}
+
+// Drain grows with the applier's siphon level and insight.
+func (s *Siphon) Drain() int {
+	return (s.Level + 1) * s.Insight
+}
+
+func (s *Siphon) Tick() world.Tick {
+	return world.Tick{
+		Source: s.Applier,
+		Spirit: -s.Drain(),
+		Absorb: true,
+		Drained: func(amount int) world.Message {
+			return world.Message{
+				FirstPerson:         s.Applier,
+				FirstPersonMessage:  fmt.Sprintf("You siphon %d spirit from %s.", amount, s.Character.Name),
+				SecondPerson:        s.Character,
+				SecondPersonMessage: fmt.Sprintf("%d of your spirit seeps away.", amount),
+			}
+		},
+	}
+}
+
+func NewSiphon(character *world.Character, applier *world.Character) *Siphon {
+	level := applier.Skills.Level(SiphonName)
+	stacking := world.Stacking{Rule: world.Refresh}
+	return &Siphon{
+		Effect:    NewEffect(12, SiphonName, stacking, world.Harmful, world.Magic),
+		Level:     level,
+		Insight:   applier.Core.Insight.Value(),
+		Character: character,
+		Applier:   applier,
+	}
+}
@@ -0,0 +1,39 @@
+package buffs
+
+import (
+	"github.com/michaelvmata/path/world"
+)
+
+var WeakenName = "weaken"
+
+// Weaken saps the strength from a character's blows, more with each stack.
+type Weaken struct {
+	Effect
+	Level int
+}
+
+func (w *Weaken) ApplyMessage() string {
+	return "Your strength drains away."
+}
+
+func (w *Weaken) UnapplyMessage() string {
+	return "Your strength returns."
+}
+
+func (w *Weaken) AlreadyApplied() string {
+	return "You feel even weaker."
+}
+
+func (w *Weaken) Upkeep() int {
+	return w.Level + 1
+}
+
+func (w *Weaken) DamageReduction() int {
+	return (w.Level + 1) * 2 * w.Stacks()
+}
+
+func NewWeaken(character *world.Character, applier *world.Character) *Weaken {
+	level := applier.Skills.Level(WeakenName)
+	stacking := world.Stacking{Rule: world.Intensify, Maximum: 3}
+	return &Weaken{Effect: NewEffect(30, WeakenName, stacking, world.Harmful, world.Magic), Level: level}
+}
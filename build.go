@@ -1,13 +1,23 @@
 package main
 
 import (
+	"github.com/michaelvmata/path/account"
 	"github.com/michaelvmata/path/items"
 	"github.com/michaelvmata/path/quest"
+	"github.com/michaelvmata/path/skills"
 	"github.com/michaelvmata/path/world"
 	"gopkg.in/yaml.v3"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	AccountsPath = "data/account.yaml"
+	PlayersPath  = "data/players"
+	SkillsPath   = "data/skills"
 )
 
 type YAMLItem struct {
@@ -16,8 +26,10 @@ type YAMLItem struct {
 	Type          string   `yaml:"Type"`
 	Description   string   `yaml:"Description"`
 	Slot          string   `yaml:"Slot"`
+	Armor         int      `yaml:"Armor"`
+	BlockRate     float64  `yaml:"BlockRate"`
 	DamageType    string   `yaml:"DamageType"`
-	Attributes    []string `yaml:"Attributes""`
+	Attributes    []string `yaml:"Attributes"`
 	Immovable     bool     `yaml:"Immovable"`
 	MinimumDamage int      `yaml:"MinimumDamage"`
 	MaximumDamage int      `yaml:"MaximumDamage"`
@@ -31,48 +43,76 @@ type YAMLItem struct {
 }
 
 type YAMLMobile struct {
-	UUID         string `yaml:"UUID"`
-	Name         string `yaml:"Name"`
-	Description  string `yaml:"Description"`
-	Essence      int    `yaml:"Essence"`
-	Power        int    `yaml:"Power"`
-	Agility      int    `yaml:"Agility"`
-	Will         int    `yaml:"Will"`
-	Insight      int    `yaml:"Insight"`
-	IsAggressive bool   `yaml:"IsAggressive"`
-	IsSocial     bool   `yaml:"IsSocial"`
-	Health       int    `yaml:"Health"`
-	Spirit       int    `yaml:"Spirit"`
-	RoomUUID     string `yaml:"RoomUUID"`
-	Anchor       string `yaml:"Anchor"`
-	Gear         struct {
-		Head     string `yaml:"Head"`
-		Neck     string `yaml:"Neck"`
-		Body     string `yaml:"Body"`
-		Arms     string `yaml:"Arms"`
-		Hands    string `yaml:"Hands"`
-		Waist    string `yaml:"Waist"`
-		Legs     string `yaml:"Legs"`
-		Feet     string `yaml:"Feet"`
-		Wrist    string `yaml:"Wrist"`
-		Fingers  string `yaml:"Fingers"`
-		OffHand  string `yaml:"OffHand"`
-		MainHand string `yaml:"MainHand"`
-	} `yaml:"Gear"`
-	Inventory []string `yaml:"Inventory"`
-	Skills    struct {
-		Barrier  int `yaml:"Barrier"`
-		Bash     int `yaml:"Bash"`
-		Backstab int `yaml:"Backstab"`
-		Bleed    int `yaml:"Bleed"`
-		Blitz    int `yaml:"Blitz"`
-		Circle   int `yaml:"Circle"`
-		Evasion  int `yaml:"Evasion"`
-		Haste    int `yaml:"Haste"`
-		Parry    int `yaml:"Parry"`
-		Sweep    int `yaml:"Sweep"`
-	} `yaml:"Skills"`
-	Quests []YAMLMobileQuests `yaml:"Quests"`
+	Version      int                `yaml:"Version,omitempty"`
+	UUID         string             `yaml:"UUID"`
+	Name         string             `yaml:"Name"`
+	Description  string             `yaml:"Description"`
+	Essence      int                `yaml:"Essence"`
+	Power        int                `yaml:"Power"`
+	Agility      int                `yaml:"Agility"`
+	Will         int                `yaml:"Will"`
+	Insight      int                `yaml:"Insight"`
+	IsAggressive bool               `yaml:"IsAggressive"`
+	IsSocial     bool               `yaml:"IsSocial"`
+	Health       int                `yaml:"Health"`
+	Spirit       int                `yaml:"Spirit"`
+	RoomUUID     string             `yaml:"RoomUUID"`
+	Anchor       string             `yaml:"Anchor"`
+	Gear         YAMLGear           `yaml:"Gear,omitempty"`
+	Inventory    []string           `yaml:"Inventory,omitempty"`
+	Skills       map[string]int     `yaml:"Skills"`
+	Quests       []YAMLMobileQuests `yaml:"Quests"`
+	Resistances  struct {
+		Crush  int `yaml:"Crush"`
+		Pierce int `yaml:"Pierce"`
+		Slash  int `yaml:"Slash"`
+	} `yaml:"Resistances,omitempty"`
+
+	// Items are the item instances a saved character carries.  Mobile
+	// prototypes list item UUIDs in Gear and Inventory instead.
+	Items []YAMLItemInstance `yaml:"Items,omitempty"`
+}
+
+// YAMLItemInstance is a saved item instance.  Slot is the gear slot the
+// item is worn in, or empty when it is in the inventory.
+type YAMLItemInstance struct {
+	UUID       string `yaml:"UUID"`
+	InstanceID string `yaml:"InstanceID"`
+	Slot       string `yaml:"Slot,omitempty"`
+	Condition  int    `yaml:"Condition"`
+}
+
+type YAMLGear struct {
+	Head     string `yaml:"Head"`
+	Neck     string `yaml:"Neck"`
+	Body     string `yaml:"Body"`
+	Arms     string `yaml:"Arms"`
+	Hands    string `yaml:"Hands"`
+	Waist    string `yaml:"Waist"`
+	Legs     string `yaml:"Legs"`
+	Feet     string `yaml:"Feet"`
+	Wrist    string `yaml:"Wrist"`
+	Fingers  string `yaml:"Fingers"`
+	OffHand  string `yaml:"OffHand"`
+	MainHand string `yaml:"MainHand"`
+}
+
+// Slots maps each gear slot to the UUID of the item worn there.
+func (g YAMLGear) Slots() map[string]string {
+	return map[string]string{
+		item.Head:     g.Head,
+		item.Neck:     g.Neck,
+		item.Body:     g.Body,
+		item.Arms:     g.Arms,
+		item.Hands:    g.Hands,
+		item.Waist:    g.Waist,
+		item.Legs:     g.Legs,
+		item.Feet:     g.Feet,
+		item.Wrist:    g.Wrist,
+		item.Fingers:  g.Fingers,
+		item.OffHand:  g.OffHand,
+		item.MainHand: g.MainHand,
+	}
 }
 
 type YAMLMobileQuests struct {
@@ -85,17 +125,12 @@ type YAMLMobileQuestStep struct {
 }
 
 type YAMLRoom struct {
-	UUID        string `yaml:"UUID"`
-	Name        string `yaml:"Name"`
-	Description string `yaml:"Description"`
-	Size        int    `yaml:"Size"`
-	Exits       struct {
-		East  string `yaml:"East"`
-		North string `yaml:"North"`
-		West  string `yaml:"West"`
-		South string `yaml:"South"`
-	} `yaml:"Exits"`
-	Mobiles []struct {
+	UUID        string              `yaml:"UUID"`
+	Name        string              `yaml:"Name"`
+	Description string              `yaml:"Description"`
+	Size        int                 `yaml:"Size"`
+	Exits       map[string]YAMLExit `yaml:"Exits"`
+	Mobiles     []struct {
 		UUID  string `yaml:"UUID"`
 		Count int    `yaml:"Count"`
 	} `yaml:"Mobiles"`
@@ -105,6 +140,31 @@ type YAMLRoom struct {
 	} `yaml:"Items"`
 }
 
+// YAMLExit is either a room UUID or a mapping with a Room and an optional
+// Door.
+type YAMLExit struct {
+	Room string `yaml:"Room"`
+	Door *struct {
+		Name  string `yaml:"Name"`
+		State string `yaml:"State"`
+		Key   string `yaml:"Key"`
+	} `yaml:"Door"`
+}
+
+func (e *YAMLExit) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&e.Room)
+	}
+	type plain YAMLExit
+	return value.Decode((*plain)(e))
+}
+
+const (
+	DoorOpen   = "open"
+	DoorClosed = "closed"
+	DoorLocked = "locked"
+)
+
 type YamlQuest struct {
 	UUID        string `yaml:"UUID"`
 	Description string `yaml:"Description"`
@@ -124,16 +184,26 @@ type YamlQuest struct {
 }
 
 type YAMLArea struct {
-	UUID    string       `yaml:"UUID"`
-	Name    string       `yaml:"Name"`
+	UUID  string `yaml:"UUID"`
+	Name  string `yaml:"Name"`
+	Reset struct {
+		Ticks     int    `yaml:"Ticks"`
+		WhenEmpty bool   `yaml:"WhenEmpty"`
+		Message   string `yaml:"Message"`
+	} `yaml:"Reset"`
 	Items   []YAMLItem   `yaml:"Items"`
 	Mobiles []YAMLMobile `yaml:"Mobiles"`
 	Rooms   []YAMLRoom   `yaml:"Rooms"`
 	Quests  []YamlQuest  `yaml:"Quests"`
 }
 
-type YAMLPlayer struct {
-	Players []YAMLMobile `yaml:"Players"`
+type YAMLAccount struct {
+	Name         string `yaml:"Name"`
+	PasswordHash string `yaml:"PasswordHash"`
+}
+
+type YAMLAccounts struct {
+	Accounts []YAMLAccount `yaml:"Accounts"`
 }
 
 func buildArea(data []byte) YAMLArea {
@@ -148,6 +218,9 @@ func buildArea(data []byte) YAMLArea {
 	if area.UUID == "" {
 		log.Fatalf("Area has no UUID")
 	}
+	if area.Reset.Ticks < 0 {
+		log.Fatalf("Area %s has negative reset Ticks", area.UUID)
+	}
 	for _, item := range area.Items {
 		validateItem(item)
 	}
@@ -176,6 +249,9 @@ func validateItem(item YAMLItem) {
 	if item.Type == "Armor" && item.Slot == "" {
 		log.Fatalf("Item has no Slot %v", item)
 	}
+	if item.Armor < 0 {
+		log.Fatalf("Item has negative Armor %v", item)
+	}
 	for _, modifier := range item.Modifiers {
 		if modifier.Type == "" {
 			log.Fatalf("Item modifier has no Type %v", item)
@@ -207,6 +283,15 @@ func validateMobile(mobile YAMLMobile) {
 	}
 }
 
+func validatePlayer(player YAMLMobile) {
+	if player.UUID == "" {
+		log.Fatalf("Player has no UUID %v", player)
+	}
+	if player.Name == "" {
+		log.Fatalf("Player has no name %v", player)
+	}
+}
+
 func validateRoom(room YAMLRoom) {
 	if room.UUID == "" {
 		log.Fatalf("Room has no UUID %v", room)
@@ -228,6 +313,31 @@ func validateRoom(room YAMLRoom) {
 			log.Fatalf("Room mobile has count 0 %v", room)
 		}
 	}
+	for name, exit := range room.Exits {
+		if exit.Room == "" {
+			log.Fatalf("Room %s exit %s has no Room", room.UUID, name)
+		}
+		if exit.Door == nil {
+			continue
+		}
+		switch exit.Door.State {
+		case "", DoorOpen, DoorClosed:
+		case DoorLocked:
+			if exit.Door.Key == "" {
+				log.Fatalf("Room %s exit %s is locked without a Key", room.UUID, name)
+			}
+		default:
+			log.Fatalf("Room %s exit %s has invalid door State %s", room.UUID, name, exit.Door.State)
+		}
+	}
+	for _, i := range room.Items {
+		if i.UUID == "" {
+			log.Fatalf("Room item has no UUID %v", room)
+		}
+		if i.Count == 0 {
+			log.Fatalf("Room item has count 0 %v", room)
+		}
+	}
 }
 
 func validateQuest(quest YamlQuest) {
@@ -269,19 +379,33 @@ func buildAreas(w *world.World, root string) {
 		}
 		yamlArea := buildAreaFromPath(root + "/" + f.Name())
 		area := world.NewArea(yamlArea.UUID, yamlArea.Name)
+		area.ResetTicks = yamlArea.Reset.Ticks
+		area.ResetWhenEmpty = yamlArea.Reset.WhenEmpty
+		area.ResetMessage = strings.TrimSpace(yamlArea.Reset.Message)
 		w.Areas[area.UUID] = area
 		buildItems(w, yamlArea)
 		buildMobiles(w, yamlArea)
 		buildRooms(w, yamlArea)
 		buildQuests(w, yamlArea)
 	}
+	linkDoors(w)
+	w.SpawnItems()
 }
 
 func buildItems(w *world.World, area YAMLArea) {
 	for _, r := range area.Items {
 		var i item.Item
 		if r.Type == item.ArmorType {
-			i = item.NewArmor(r.UUID, r.Name, r.Slot, r.Keywords, r.Description)
+			armor := item.NewArmor(r.UUID, r.Name, r.Slot, r.Keywords, r.Description)
+			armor.Rating = r.Armor
+			i = armor
+		} else if r.Type == item.ShieldType {
+			shield := item.NewShield(r.UUID, r.Name, r.Keywords, r.Description)
+			if r.BlockRate < 0 || r.BlockRate > 1 {
+				log.Fatalln("Invalid BlockRate", r)
+			}
+			shield.BlockRate = r.BlockRate
+			i = shield
 		} else if r.Type == item.WeaponType {
 			w := item.NewWeapon(r.UUID, r.Name, r.Keywords, r.Description, r.DamageType, r.Attributes)
 			if r.MaximumDamage <= r.MinimumDamage || r.MinimumDamage <= 0 {
@@ -301,7 +425,7 @@ func buildItems(w *world.World, area YAMLArea) {
 		for _, rm := range r.Modifiers {
 			i.AddModifier(rm.Type, rm.Value)
 		}
-		w.Items[i.UUID()] = i
+		w.Items.AddPrototype(i)
 	}
 }
 
@@ -318,16 +442,51 @@ func buildRooms(w *world.World, yamlArea YAMLArea) {
 			w.RoomMobiles[room.UUID] = append(w.RoomMobiles[room.UUID], world.NewRoomMobile(mc.UUID, mc.Count))
 		}
 		for _, yamlItem := range rr.Items {
-			i, found := w.Items[yamlItem.UUID]
-			if !found {
+			if _, found := w.Items.Prototypes[yamlItem.UUID]; !found {
 				log.Fatalf("Can't find item %s", yamlItem.UUID)
 			}
-			room.Accept(i)
+			w.RoomItems[room.UUID] = append(w.RoomItems[room.UUID], world.NewRoomItem(yamlItem.UUID, yamlItem.Count))
+		}
+		for name, re := range rr.Exits {
+			room.Exits[strings.ToLower(name)] = buildExit(re)
+		}
+	}
+}
+
+func buildExit(re YAMLExit) *world.Exit {
+	exit := &world.Exit{RoomUUID: re.Room}
+	if re.Door != nil {
+		exit.Door = &world.Door{
+			Name:    re.Door.Name,
+			Closed:  re.Door.State == DoorClosed || re.Door.State == DoorLocked,
+			Locked:  re.Door.State == DoorLocked,
+			KeyUUID: re.Door.Key,
+		}
+		if exit.Door.Name == "" {
+			exit.Door.Name = "door"
+		}
+	}
+	return exit
+}
+
+// linkDoors makes both sides of a passage share one door, so that a door
+// opened from one room is open from the other.
+func linkDoors(w *world.World) {
+	for _, room := range w.Rooms {
+		for _, exit := range room.Exits {
+			if exit.Door == nil {
+				continue
+			}
+			other, ok := w.Rooms[exit.RoomUUID]
+			if !ok {
+				continue
+			}
+			for _, back := range other.Exits {
+				if back.RoomUUID == room.UUID && back.Door != nil {
+					back.Door = exit.Door
+				}
+			}
 		}
-		room.Exits.East = rr.Exits.East
-		room.Exits.North = rr.Exits.North
-		room.Exits.South = rr.Exits.South
-		room.Exits.West = rr.Exits.West
 	}
 }
 
@@ -348,221 +507,136 @@ func buildQuests(w *world.World, yamlArea YAMLArea) {
 	}
 }
 
-func savePlayers(players map[string]*world.Character) {
-	yamlPlayer := YAMLPlayer{
-		Players: make([]YAMLMobile, 0),
+func yamlFromPlayer(player *world.Character) YAMLMobile {
+	p := YAMLMobile{Version: CharacterVersion}
+	p.Name = player.Name
+	p.UUID = player.UUID
+	p.Description = player.Description
+	p.Essence = player.Essence
+	p.RoomUUID = player.Room.UUID
+	if player.Anchor != nil {
+		p.Anchor = player.Anchor.UUID
 	}
-	for _, player := range players {
-		p := YAMLMobile{Inventory: make([]string, 0)}
-		p.Name = player.Name
-		p.UUID = player.UUID
-		p.Essence = player.Essence
-		p.RoomUUID = player.Room.UUID
-
-		p.Power = player.Core.Power.Base
-		p.Agility = player.Core.Agility.Base
-		p.Insight = player.Core.Insight.Base
-		p.Will = player.Core.Will.Base
 
-		p.Health = player.Health.Current
-		p.Spirit = player.Spirit.Current
-
-		if player.Gear.Head != nil {
-			p.Gear.Head = player.Gear.Head.UUID()
-		}
-		if player.Gear.Neck != nil {
-			p.Gear.Neck = player.Gear.Neck.UUID()
-		}
-		if player.Gear.Body != nil {
-			p.Gear.Body = player.Gear.Body.UUID()
-		}
-		if player.Gear.Arms != nil {
-			p.Gear.Arms = player.Gear.Arms.UUID()
-		}
-		if player.Gear.Hands != nil {
-			p.Gear.Hands = player.Gear.Hands.UUID()
-		}
-		if player.Gear.Waist != nil {
-			p.Gear.Waist = player.Gear.Waist.UUID()
-		}
-		if player.Gear.Legs != nil {
-			p.Gear.Legs = player.Gear.Legs.UUID()
-		}
-		if player.Gear.Feet != nil {
-			p.Gear.Feet = player.Gear.Feet.UUID()
-		}
-		if player.Gear.Wrist != nil {
-			p.Gear.Wrist = player.Gear.Wrist.UUID()
-		}
-		if player.Gear.Fingers != nil {
-			p.Gear.Fingers = player.Gear.Fingers.UUID()
-		}
-		if player.Gear.MainHand != nil {
-			p.Gear.MainHand = player.Gear.MainHand.UUID()
-		}
-		if player.Gear.OffHand != nil {
-			p.Gear.OffHand = player.Gear.OffHand.UUID()
-		}
+	p.Power = player.Core.Power.Base
+	p.Agility = player.Core.Agility.Base
+	p.Insight = player.Core.Insight.Base
+	p.Will = player.Core.Will.Base
+	p.Resistances.Crush = player.Resistances.Crush.Base
+	p.Resistances.Pierce = player.Resistances.Pierce.Base
+	p.Resistances.Slash = player.Resistances.Slash.Base
 
-		for _, i := range player.Inventory.Items {
-			p.Inventory = append(p.Inventory, i.UUID())
-		}
-		p.Skills.Barrier = player.Skills.Barrier.Base
-		p.Skills.Bash = player.Skills.Bash.Base
-		p.Skills.Backstab = player.Skills.Backstab.Base
-		p.Skills.Bleed = player.Skills.Bleed.Base
-		p.Skills.Blitz = player.Skills.Blitz.Base
-		p.Skills.Circle = player.Skills.Circle.Base
-		p.Skills.Evasion = player.Skills.Evasion.Base
-		p.Skills.Haste = player.Skills.Haste.Base
-		p.Skills.Parry = player.Skills.Parry.Base
-		p.Skills.Sweep = player.Skills.Sweep.Base
+	p.Health = player.Health.Current
+	p.Spirit = player.Spirit.Current
 
-		for _, q := range player.Quests {
-			steps := make([]YAMLMobileQuestStep, 0)
-			for _, s := range q.Steps {
-				current, _ := s.Progress()
-				steps = append(steps, YAMLMobileQuestStep{Current: current})
-			}
-			p.Quests = append(p.Quests, YAMLMobileQuests{
-				UUID:  q.UUID,
-				Steps: steps,
-			})
+	for _, slot := range item.Slots {
+		if i := player.Gear.At(slot); i != nil {
+			p.Items = append(p.Items, yamlFromItem(i, slot))
 		}
-		yamlPlayer.Players = append(yamlPlayer.Players, p)
 	}
-	data, err := yaml.Marshal(&yamlPlayer)
-	if err != nil {
-		log.Fatalf("Unable to marshal players yaml")
+	for _, i := range player.Inventory.Items {
+		p.Items = append(p.Items, yamlFromItem(i, ""))
 	}
-	yamlFile, err := os.OpenFile("data/player.yaml", os.O_WRONLY|os.O_CREATE, 0666)
-	if err != nil {
-		log.Fatalf("Unable to open players file")
+	p.Skills = make(map[string]int, len(player.Skills))
+	for name, skill := range player.Skills {
+		p.Skills[name] = skill.Base
 	}
-	if err := yamlFile.Truncate(0); err != nil {
-		log.Fatalf("Unable to truncate player file")
+
+	for _, q := range player.Quests {
+		steps := make([]YAMLMobileQuestStep, 0)
+		for _, s := range q.Steps {
+			current, _ := s.Progress()
+			steps = append(steps, YAMLMobileQuestStep{Current: current})
+		}
+		p.Quests = append(p.Quests, YAMLMobileQuests{
+			UUID:  q.UUID,
+			Steps: steps,
+		})
 	}
-	len, err := yamlFile.Write(data)
-	if err != nil {
-		log.Fatalf("Unable to write players file")
+	return p
+}
+
+func yamlFromItem(i item.Item, slot string) YAMLItemInstance {
+	return YAMLItemInstance{
+		UUID:       i.UUID(),
+		InstanceID: i.InstanceID(),
+		Slot:       slot,
+		Condition:  i.Condition(),
 	}
-	log.Printf("Wrote %d bytes to players file.", len)
-	yamlFile.Close()
 }
 
-func buildPlayers(w *world.World) {
-	data := buildPlayerFromPath("data/player.yaml")
-	players := YAMLPlayer{}
-	err := yaml.Unmarshal(data, &players)
+func buildPlayers(w *world.World, store Store) {
+	UUIDs, err := store.ListCharacters()
 	if err != nil {
-		log.Fatalf("error: %v", err)
+		log.Fatalf("Unable to list characters: %v", err)
 	}
-	for _, rp := range players.Players {
-		validateMobile(rp)
-		c := world.NewPlayer(rp.UUID, rp.Name)
-
-		c.Essence = rp.Essence
-		c.Core.Power.Base = rp.Power
-		c.Core.Agility.Base = rp.Agility
-		c.Core.Insight.Base = rp.Insight
-		c.Core.Will.Base = rp.Will
-
-		c.Health.Current = rp.Health
-		c.Spirit.Current = rp.Spirit
-
-		if rp.Gear.Head != "" {
-			if i, ok := w.Items[rp.Gear.Head]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Neck != "" {
-			if i, ok := w.Items[rp.Gear.Neck]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Body != "" {
-			if i, ok := w.Items[rp.Gear.Body]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Arms != "" {
-			if i, ok := w.Items[rp.Gear.Arms]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Hands != "" {
-			if i, ok := w.Items[rp.Gear.Hands]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Waist != "" {
-			if i, ok := w.Items[rp.Gear.Waist]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Legs != "" {
-			if i, ok := w.Items[rp.Gear.Legs]; ok {
-				c.Gear.Equip(i)
-			}
+	for _, UUID := range UUIDs {
+		if _, err := store.LoadCharacter(w, UUID); err != nil {
+			log.Fatalf("Unable to load character %s: %v", UUID, err)
 		}
-		if rp.Gear.Feet != "" {
-			if i, ok := w.Items[rp.Gear.Feet]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Wrist != "" {
-			if i, ok := w.Items[rp.Gear.Wrist]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Fingers != "" {
-			if i, ok := w.Items[rp.Gear.Fingers]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.OffHand != "" {
-			if i, ok := w.Items[rp.Gear.OffHand]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.MainHand != "" {
-			if i, ok := w.Items[rp.Gear.MainHand]; ok {
-				c.Gear.Equip(i)
-			}
+	}
+}
+
+func buildPlayer(w *world.World, rp YAMLMobile) *world.Character {
+	validatePlayer(rp)
+	c := world.NewPlayer(rp.UUID, rp.Name)
+
+	c.Description = rp.Description
+	c.Essence = rp.Essence
+	c.Core.Power.Base = rp.Power
+	c.Core.Agility.Base = rp.Agility
+	c.Core.Insight.Base = rp.Insight
+	c.Core.Will.Base = rp.Will
+	c.Resistances.Crush.Base = rp.Resistances.Crush
+	c.Resistances.Pierce.Base = rp.Resistances.Pierce
+	c.Resistances.Slash.Base = rp.Resistances.Slash
+
+	c.Health.Current = rp.Health
+	c.Spirit.Current = rp.Spirit
+
+	// The main hand goes first so a two handed weapon blocks the off hand.
+	sort.SliceStable(rp.Items, func(a, b int) bool {
+		return rp.Items[a].Slot == item.MainHand && rp.Items[b].Slot != item.MainHand
+	})
+	for _, yi := range rp.Items {
+		if yi.InstanceID == "" {
+			yi.InstanceID = item.NewInstanceID()
+		}
+		i, ok := w.Items.Restore(yi.UUID, yi.InstanceID)
+		if !ok {
+			continue
 		}
-		for _, itemUUID := range rp.Inventory {
-			if i, ok := w.Items[itemUUID]; ok {
-				c.Inventory.AddItem(i)
-			}
+		i.SetCondition(yi.Condition)
+		if yi.Slot == "" {
+			c.Inventory.AddItem(i)
+		} else if _, err := c.Gear.EquipIn(i, yi.Slot); err != nil {
+			c.Inventory.AddItem(i)
 		}
-		c.Skills.Backstab.Base = rp.Skills.Backstab
-		c.Skills.Bash.Base = rp.Skills.Bash
-		c.Skills.Barrier.Base = rp.Skills.Barrier
-		c.Skills.Bleed.Base = rp.Skills.Bleed
-		c.Skills.Blitz.Base = rp.Skills.Blitz
-		c.Skills.Circle.Base = rp.Skills.Circle
-		c.Skills.Evasion.Base = rp.Skills.Evasion
-		c.Skills.Haste.Base = rp.Skills.Haste
-		c.Skills.Parry.Base = rp.Skills.Parry
-		c.Skills.Sweep.Base = rp.Skills.Sweep
-		c.Update(0)
-		w.Players[c.Name] = c
-		if room, ok := w.Rooms[rp.RoomUUID]; ok {
-			if err := room.Enter(c); err == nil {
-				c.Room = room
-			}
+	}
+	for name, level := range rp.Skills {
+		if _, ok := w.Skills[name]; !ok {
+			log.Printf("Player %s has unknown skill %s", rp.Name, name)
 		}
-		if room, ok := w.Rooms[rp.Anchor]; ok {
-			c.Anchor = room
+		c.Skills.Get(name).Base = level
+	}
+	c.Update(0)
+	w.Players[c.Name] = c
+	if room, ok := w.Rooms[rp.RoomUUID]; ok {
+		if err := room.Enter(c); err == nil {
+			c.Room = room
 		}
-		for _, yq := range rp.Quests {
-			q, ok := w.Quests[yq.UUID]
-			if !ok {
-				log.Fatalf("Could not find quest %s", yq.UUID)
-			}
-			c.Quests = append(c.Quests, q.Clone(c.UUID))
+	}
+	if room, ok := w.Rooms[rp.Anchor]; ok {
+		c.Anchor = room
+	}
+	for _, yq := range rp.Quests {
+		q, ok := w.Quests[yq.UUID]
+		if !ok {
+			log.Fatalf("Could not find quest %s", yq.UUID)
 		}
+		c.Quests = append(c.Quests, q.Clone(c.UUID))
 	}
+	return c
 }
 
 func buildMobiles(w *world.World, area YAMLArea) {
@@ -575,69 +649,26 @@ func buildMobiles(w *world.World, area YAMLArea) {
 		c.Core.Agility.Base = rp.Agility
 		c.Core.Insight.Base = rp.Insight
 		c.Core.Will.Base = rp.Will
+		c.Resistances.Crush.Base = rp.Resistances.Crush
+		c.Resistances.Pierce.Base = rp.Resistances.Pierce
+		c.Resistances.Slash.Base = rp.Resistances.Slash
 
-		if rp.Gear.Head != "" {
-			if i, ok := w.Items[rp.Gear.Head]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Neck != "" {
-			if i, ok := w.Items[rp.Gear.Neck]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Body != "" {
-			if i, ok := w.Items[rp.Gear.Body]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Arms != "" {
-			if i, ok := w.Items[rp.Gear.Arms]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Hands != "" {
-			if i, ok := w.Items[rp.Gear.Hands]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Waist != "" {
-			if i, ok := w.Items[rp.Gear.Waist]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Legs != "" {
-			if i, ok := w.Items[rp.Gear.Legs]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Feet != "" {
-			if i, ok := w.Items[rp.Gear.Feet]; ok {
-				c.Gear.Equip(i)
+		slots := rp.Gear.Slots()
+		// The main hand goes first so a two handed weapon blocks the off
+		// hand.
+		for _, slot := range append([]string{item.MainHand}, item.Slots...) {
+			itemUUID := slots[slot]
+			if itemUUID == "" || c.Gear.At(slot) != nil {
+				continue
 			}
-		}
-		if rp.Gear.Wrist != "" {
-			if i, ok := w.Items[rp.Gear.Wrist]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.Fingers != "" {
-			if i, ok := w.Items[rp.Gear.Fingers]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.OffHand != "" {
-			if i, ok := w.Items[rp.Gear.OffHand]; ok {
-				c.Gear.Equip(i)
-			}
-		}
-		if rp.Gear.MainHand != "" {
-			if i, ok := w.Items[rp.Gear.MainHand]; ok {
-				c.Gear.Equip(i)
+			if i, ok := w.Items.Prototypes[itemUUID]; ok {
+				if _, err := c.Gear.EquipIn(i, slot); err != nil {
+					log.Fatalf("Mobile %s can't wear %s in %s: %v", rp.UUID, itemUUID, slot, err)
+				}
 			}
 		}
 		for _, itemUUID := range rp.Inventory {
-			if i, ok := w.Items[itemUUID]; ok {
+			if i, ok := w.Items.Prototypes[itemUUID]; ok {
 				c.Inventory.AddItem(i)
 			}
 		}
@@ -652,21 +683,57 @@ func buildMobiles(w *world.World, area YAMLArea) {
 	}
 }
 
-func buildPlayerFromPath(path string) []byte {
-	absPath, err := filepath.Abs(path)
+func buildAccounts(path string) *account.Accounts {
+	accounts := account.New()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return accounts
+	}
 	if err != nil {
-		log.Fatalf("Player path error")
+		log.Fatalf("Error reading accounts file %s", path)
 	}
-	data, err := os.ReadFile(absPath)
+	yamlAccounts := YAMLAccounts{}
+	if err := yaml.Unmarshal(data, &yamlAccounts); err != nil {
+		log.Fatalf("error: %v", err)
+	}
+	for _, ya := range yamlAccounts.Accounts {
+		if ya.Name == "" || ya.PasswordHash == "" {
+			log.Fatalf("Account missing Name or PasswordHash %v", ya.Name)
+		}
+		accounts.Add(ya.Name, ya.PasswordHash)
+	}
+	return accounts
+}
+
+func saveAccounts(accounts *account.Accounts, path string) error {
+	yamlAccounts := YAMLAccounts{Accounts: make([]YAMLAccount, 0)}
+	for _, a := range accounts.All() {
+		yamlAccounts.Accounts = append(yamlAccounts.Accounts, YAMLAccount{
+			Name:         a.Name,
+			PasswordHash: a.PasswordHash,
+		})
+	}
+	sort.Slice(yamlAccounts.Accounts, func(i, j int) bool {
+		return yamlAccounts.Accounts[i].Name < yamlAccounts.Accounts[j].Name
+	})
+	data, err := yaml.Marshal(&yamlAccounts)
 	if err != nil {
-		log.Fatalf("Error reading player YAML file")
+		return err
 	}
-	return data
+	return writeFileAtomic(path, data)
 }
 
 func build(root string) *world.World {
+	return buildFromStore(NewYAMLStore(root, PlayersPath))
+}
+
+func buildFromStore(store Store) *world.World {
 	world := world.NewWorld()
-	buildAreas(world, root)
-	buildPlayers(world)
+	world.Skills = skills.Build(SkillsPath)
+	checkTechniques(world.Skills)
+	if err := store.LoadAreas(world); err != nil {
+		log.Fatalf("Unable to load areas: %v", err)
+	}
+	buildPlayers(world, store)
 	return world
 }
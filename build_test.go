@@ -1,8 +1,10 @@
 package main
 
 import (
+	"github.com/michaelvmata/path/world"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -31,9 +33,99 @@ func TestBuild(t *testing.T) {
 	}
 }
 
+// describePlayer sets the fields only players with a history have.
+func describePlayer(player *world.Character) {
+	player.Description = "A weathered traveler."
+	player.Resistances.Crush.Base = 10
+	player.Resistances.Pierce.Base = -5
+	player.Resistances.Slash.Base = 20
+}
+
+func checkDescribedPlayer(t *testing.T, player *world.Character) {
+	if player.Description != "A weathered traveler." {
+		t.Fatalf("Description not saved %q", player.Description)
+	}
+	r := player.Resistances
+	if r.Crush.Base != 10 || r.Pierce.Base != -5 || r.Slash.Base != 20 {
+		t.Fatalf("Resistances not saved %d %d %d", r.Crush.Base, r.Pierce.Base, r.Slash.Base)
+	}
+}
+
+func TestSQLStore(t *testing.T) {
+	w := build("data/areas")
+	store, err := NewSQLStore("data/areas", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Unable to open sqlite store %v", err)
+	}
+	defer store.Close()
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("Migrating twice failed %v", err)
+	}
+	if version, _ := store.Version(); version != len(sqlMigrations) {
+		t.Fatalf("Schema version(%d) expected(%d)", version, len(sqlMigrations))
+	}
+	worn := w.Players["gaigen"].Inventory.Items[0]
+	worn.SetCondition(40)
+	describePlayer(w.Players["gaigen"])
+	saveCharacters(store, w.Players)
+	saveCharacters(store, w.Players)
+
+	saved := buildFromStore(store)
+	if i := saved.Items.Instances[worn.InstanceID()]; i == nil || i.Condition() != 40 {
+		t.Fatalf("Item condition not saved")
+	}
+	checkDescribedPlayer(t, saved.Players["gaigen"])
+	for name, player := range w.Players {
+		loaded := saved.Players[name]
+		if loaded == nil {
+			t.Fatalf("Player %s not saved", name)
+		}
+		expected, actual := yamlFromPlayer(player), yamlFromPlayer(loaded)
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("Loaded player %v expected %v", actual, expected)
+		}
+	}
+}
+
 func TestSavePlayers(t *testing.T) {
-	world := build("data/areas")
-	savePlayers(world.Players)
+	w := build("data/areas")
+	store := NewYAMLStore("data/areas", t.TempDir())
+	worn := w.Players["gaigen"].Inventory.Items[0]
+	worn.SetCondition(40)
+	describePlayer(w.Players["gaigen"])
+	saveCharacters(store, w.Players)
+
+	saved := buildFromStore(store)
+	checkDescribedPlayer(t, saved.Players["gaigen"])
+	if i := saved.Items.Instances[worn.InstanceID()]; i == nil || i.Condition() != 40 {
+		t.Fatalf("Item condition not saved")
+	}
+	if len(saved.Players) != len(w.Players) {
+		t.Fatalf("Saved players(%d) expected(%d)", len(saved.Players), len(w.Players))
+	}
+	for name, player := range w.Players {
+		if saved.Players[name] == nil || saved.Players[name].UUID != player.UUID {
+			t.Fatalf("Player %s not saved", name)
+		}
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.yaml")
+	if err := writeFileAtomic(path, []byte("first")); err != nil {
+		t.Fatalf("Unable to write file %v", err)
+	}
+	if err := writeFileAtomic(path, []byte("second")); err != nil {
+		t.Fatalf("Unable to overwrite file %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "second" {
+		t.Fatalf("Unexpected file contents %s", data)
+	}
+	nodes, _ := os.ReadDir(filepath.Dir(path))
+	if len(nodes) != 1 {
+		t.Fatalf("Temporary files left behind %v", nodes)
+	}
 }
 
 func TestArea(t *testing.T) {
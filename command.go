@@ -18,6 +18,7 @@ import (
 
 type Context struct {
 	World  *world.World
+	Store  Store
 	Player *world.Character
 	Help   map[string]help.YAMLHelp
 	Raw    string
@@ -27,8 +28,9 @@ func IsWieldingRange(character *world.Character) bool {
 	return !item.IsNil(character.Gear.MainHand) && character.Gear.MainHand.IsRange()
 }
 
-func IsWieldingBlade(attacker *world.Character) bool {
-	return !item.IsNil(attacker.Gear.MainHand) && attacker.Gear.MainHand.IsBlade()
+// IsWielding is true when the main hand weapon has the attribute.
+func IsWielding(character *world.Character, attribute string) bool {
+	return !item.IsNil(character.Gear.MainHand) && character.Gear.MainHand.HasAttribute(attribute)
 }
 
 func FindTarget(attacker *world.Character, command string) *world.Character {
@@ -40,12 +42,24 @@ func FindTarget(attacker *world.Character, command string) *world.Character {
 	return attacker.Room.GetPlayer(handle)
 }
 
-func CanUseSkill(attacker *world.Character, skill string, level int, cost int) bool {
-	if level == 0 {
+// CanUseSkill checks the attacker knows the skill, wields the weapon it
+// needs, has the spirit for it and isn't waiting on its cool down.
+func CanUseSkill(World *world.World, attacker *world.Character, skill string) bool {
+	if attacker.IsSilenced() {
+		attacker.Showln("You can't %s while silenced.", skill)
+		return false
+	}
+	level := attacker.Skills.Level(skill)
+	if level <= 0 {
 		attacker.Showln("You gotta learn how to %s first.", skill)
 		return false
 	}
-	if !attacker.Spirit.IsAvailable(cost) {
+	definition := World.Skills[skill]
+	if definition.Weapon != "" && !IsWielding(attacker, definition.Weapon) {
+		attacker.Showln("You need to wield a %s weapon to %s.", definition.Weapon, skill)
+		return false
+	}
+	if !attacker.Spirit.IsAvailable(definition.Spirit.At(level)) {
 		attacker.Showln("Your spirit isn't strong enough to %s.", skill)
 		return false
 	}
@@ -56,12 +70,21 @@ func CanUseSkill(attacker *world.Character, skill string, level int, cost int) b
 	return true
 }
 
-func InitBattleSkill(attacker *world.Character, defender *world.Character, spirit int, skill string, coolDownDuration int) {
-	attacker.Spirit.Consume(spirit)
+// UseSkill pays the skill's spirit and starts its cool down.
+func UseSkill(World *world.World, attacker *world.Character, skill string) {
+	definition := World.Skills[skill]
+	level := attacker.Skills.Level(skill)
+	attacker.Spirit.Consume(definition.Spirit.At(level))
+	if duration := definition.CoolDown.At(level); duration > 0 {
+		coolDown := buffs.NewCoolDown(duration, skill)
+		attacker.ApplyCoolDown(&coolDown)
+	}
+}
+
+func InitBattleSkill(World *world.World, attacker *world.Character, defender *world.Character, skill string) {
+	UseSkill(World, attacker, skill)
 	attacker.StartAttacking(defender)
 	defender.StartAttacking(attacker)
-	coolDown := buffs.NewCoolDown(coolDownDuration, skill)
-	attacker.ApplyCoolDown(&coolDown)
 }
 
 type Anchor struct{}
@@ -85,6 +108,33 @@ func (a Anchor) Label() string {
 	return "anchor"
 }
 
+type Assist struct{}
+
+func (a Assist) Execute(ctx Context) {
+	player := ctx.Player
+	parts := strings.SplitN(ctx.Raw, " ", 2)
+	if len(parts) == 1 {
+		player.Showln("Assist who?")
+		return
+	}
+	ally := player.Room.GetPlayer(parts[1])
+	if ally == nil || ally == player {
+		player.Showln("You don't see '%s'.", parts[1])
+		return
+	}
+	if player.IsFighting() {
+		player.Showln("You're already fighting.")
+		return
+	}
+	if !player.Assist(ally) {
+		player.Showln("%s isn't fighting anyone.", ally.Name)
+	}
+}
+
+func (a Assist) Label() string {
+	return "assist"
+}
+
 type Attack struct{}
 
 func (a Attack) Execute(ctx Context) {
@@ -122,7 +172,8 @@ type Affect struct{}
 
 func (a Affect) Execute(ctx Context) {
 	player := ctx.Player
-	if len(player.Buffs) == 0 {
+	controls := player.Controls.Describe()
+	if len(player.Buffs) == 0 && len(controls) == 0 {
 		player.Showln("You are not affected by any technique.")
 		return
 	}
@@ -131,7 +182,15 @@ func (a Affect) Execute(ctx Context) {
 	player.ShowDivider()
 	player.ShowNewline()
 	for _, buff := range player.Buffs {
-		player.Showln(fmt.Sprintf("Name: %s\tDuration: %d", buff.Name(), buff.Remaining()))
+		categories := make([]string, 0, len(buff.Categories()))
+		for _, category := range buff.Categories() {
+			categories = append(categories, string(category))
+		}
+		player.Showln(fmt.Sprintf("Name: %s\tDuration: %d\tStacks: %d\tCategories: %s",
+			buff.Name(), buff.Remaining(), buff.Stacks(), strings.Join(categories, ", ")))
+	}
+	for _, control := range controls {
+		player.Showln(control)
 	}
 }
 
@@ -139,71 +198,21 @@ func (a Affect) Label() string {
 	return "affect"
 }
 
-type Backstab struct{}
-
-func (b Backstab) Execute(ctx Context) {
-	attacker := ctx.Player
-	level := attacker.Skills.Backstab.Value()
-	if !CanUseSkill(attacker, b.Label(), level, level) {
-		return
-	}
-
-	if attacker.IsFighting() {
-		attacker.Showln("You can't backstab while in combat.")
-		return
-	}
-
-	if !IsWieldingBlade(attacker) {
-		attacker.Showln("You can't backstab without a blade.")
-		return
-	}
-
-	defender := FindTarget(attacker, ctx.Raw)
-	if defender == nil {
-		attacker.Showln("Backstab who?")
-		return
-	}
-
-	cost := level
-	coolDown := level
-	InitBattleSkill(attacker, defender, cost, b.Label(), coolDown)
-
-	b.DoBackstab(attacker, defender, level)
-}
-
-func (b Backstab) DoBackstab(attacker *world.Character, defender *world.Character, level int) {
-	defender.Memory.AddGameEvent(b.Label(), 18)
-	hitDamage := simulate.CalculateHitDamage(attacker, defender)
-	amount := hitDamage.Amount * 10
-
-	message := world.Message{
-		FirstPersonMessage:  fmt.Sprintf("You backstab %s for %d damage.", defender.Name, amount),
-		FirstPerson:         attacker,
-		SecondPersonMessage: fmt.Sprintf("%s backstabs you for %d damage.", attacker.Name, amount),
-		SecondPerson:        defender,
-		ThirdPersonMessage:  fmt.Sprintf("%s backstabs %s for %d damage.", attacker.Name, defender.Name, amount),
-	}
-	if err := attacker.Room.ShowMessage(message); err != nil {
-		log.Fatalf("Problem showing backstab message: %v", err)
-	}
-	simulate.DoDamage(attacker, defender, amount)
-}
-
-func (b Backstab) Label() string {
-	return "backstab"
-}
-
 type Barrier struct{}
 
 func (b Barrier) Execute(ctx Context) {
 	player := ctx.Player
-	if !player.Skills.Barrier.IsAvailable() {
+	if player.IsSilenced() {
+		player.Showln("You can't form a barrier while silenced.")
+		return
+	}
+	if player.Skills.Level(b.Label()) <= 0 {
 		player.Showln("You fail to form a barrier.")
 		return
 	}
 	buff := buffs.NewBarrier(player)
-	if player.HasBuff(buff.Name()) {
-		player.Unapply(buff.Name())
+	if applied := player.GetBuff(buff.Name()); applied != nil {
+		player.Unapply(applied)
 	} else {
 		player.Apply(buff)
 	}
@@ -213,210 +222,112 @@ func (b Barrier) Label() string {
 	return "barrier"
 }
 
-type Bash struct{}
+// Cleanse removes harmful effects from the player or an ally, one more
+// effect for each level.
+type Cleanse struct{}
 
-func (b Bash) Execute(ctx Context) {
-	attacker := ctx.Player
-	level := attacker.Skills.Bash.Value()
-	if !CanUseSkill(attacker, b.Label(), level, level) {
+func (c Cleanse) Execute(ctx Context) {
+	player := ctx.Player
+	if !CanUseSkill(ctx.World, player, c.Label()) {
 		return
 	}
-	defender := FindTarget(attacker, ctx.Raw)
-	if defender == nil {
-		attacker.Showln("Bash who?")
-		return
+	target := player
+	if parts := strings.SplitN(ctx.Raw, " ", 2); len(parts) == 2 {
+		target = player.Room.GetPlayer(parts[1])
+		if target == nil {
+			player.Showln("You don't see '%s'.", parts[1])
+			return
+		}
 	}
-	attacker.Spirit.Consume(level)
-	attacker.StartAttacking(defender)
-
-	amount := b.CalculateDamage(level)
-	attacker.Showln("You bash %s for %d damage.", defender.Name, amount)
-	defender.StartAttacking(attacker)
-	defender.Showln("%s bashes you for %d damage.", attacker.Name, amount)
-	defender.Stun(1)
-
-	simulate.DoDamage(attacker, defender, amount)
-
-	coolDown := buffs.NewCoolDown(9, "bash")
-	attacker.ApplyCoolDown(&coolDown)
-}
-
-func (b Bash) CalculateDamage(level int) int {
-	return 10 + (level * 2)
-}
-
-func (b Bash) Label() string {
-	return "bash"
+	UseSkill(ctx.World, player, c.Label())
+	removed := target.Dispel(player.Skills.Level(c.Label()), world.Harmful)
+	c.ShowCleanse(player, target, len(removed))
 }
 
-type Bleed struct{}
-
-func (b Bleed) Execute(ctx Context) {
-	attacker := ctx.Player
-	level := attacker.Skills.Blitz.Value()
-	if !CanUseSkill(attacker, b.Label(), level, level) {
-		return
-	}
-
-	defender := FindTarget(attacker, ctx.Raw)
-	if defender == nil {
-		attacker.Showln("Bleed who?")
+func (c Cleanse) ShowCleanse(player *world.Character, target *world.Character, removed int) {
+	if removed == 0 {
+		player.Showln("There's nothing to cleanse.")
 		return
 	}
-
-	InitBattleSkill(attacker, defender, level, b.Label(), level)
-	b.DoBleed(ctx.World, attacker, defender, level)
-}
-
-func (b Bleed) DoBleed(World *world.World, attacker *world.Character, defender *world.Character, level int) {
 	message := world.Message{
-		FirstPerson:         attacker,
-		SecondPerson:        defender,
-		FirstPersonMessage:  fmt.Sprintf("You strike %s precisely, inflicting a grievous wound.", defender.Name),
-		SecondPersonMessage: fmt.Sprintf("%s strikes you precisely, inflicting a grievous wound.", attacker.Name),
-		ThirdPersonMessage:  fmt.Sprintf("%s stirkes %s precisely, inflicting a grievous wound.", attacker.Name, defender.Name),
+		FirstPerson:        player,
+		FirstPersonMessage: "You cleanse yourself.",
+		ThirdPersonMessage: fmt.Sprintf("%s cleanses themself.", player.Name),
 	}
-	attacker.Room.ShowMessage(message)
-	buff := buffs.NewBleed(defender, attacker)
-	defender.Apply(buff)
-}
-
-func (b Bleed) Label() string {
-	return "bleed"
-}
-
-type Blitz struct{}
-
-func (b Blitz) Execute(ctx Context) {
-	attacker := ctx.Player
-	level := attacker.Skills.Blitz.Value()
-	if !CanUseSkill(attacker, b.Label(), level, level) {
-		return
+	if target != player {
+		message.SecondPerson = target
+		message.FirstPersonMessage = fmt.Sprintf("You cleanse %s.", target.Name)
+		message.SecondPersonMessage = fmt.Sprintf("%s cleanses you.", player.Name)
+		message.ThirdPersonMessage = fmt.Sprintf("%s cleanses %s.", player.Name, target.Name)
 	}
-
-	defender := FindTarget(attacker, ctx.Raw)
-	if defender == nil {
-		attacker.Showln("Blitz who?")
-		return
+	if err := player.Room.ShowMessage(message); err != nil {
+		log.Fatalf("Problem showing cleanse message: %v", err)
 	}
+}
 
-	InitBattleSkill(attacker, defender, level, b.Label(), level)
-	b.DoBlitz(attacker, defender, level)
+func (c Cleanse) Label() string {
+	return "cleanse"
 }
 
-func (b Blitz) DoBlitz(attacker *world.Character, defender *world.Character, level int) {
-	defender.Memory.AddGameEvent(b.Label(), 18)
-	for i := 0; i <= level; i++ {
-		hitDamage := simulate.CalculateHitDamage(attacker, defender)
-		amount := int(float64(10+level) / float64(100) * float64(hitDamage.Amount))
+type Die struct{}
 
-		message := world.Message{
-			FirstPersonMessage:  fmt.Sprintf("You blitz %s for %d damage.", defender.Name, amount),
-			FirstPerson:         attacker,
-			SecondPersonMessage: fmt.Sprintf("%s blitzes you for %d damage.", attacker.Name, amount),
-			SecondPerson:        defender,
-			ThirdPersonMessage:  fmt.Sprintf("%s blitzes %s for %d damage.", attacker.Name, defender.Name, amount),
-		}
-		if err := attacker.Room.ShowMessage(message); err != nil {
-			log.Fatalf("Problem showing blitz message: %v", err)
-		}
-		simulate.DoDamage(attacker, defender, amount)
+func (d Die) Execute(ctx Context) {
+	player := ctx.Player
+	amount := player.Health.Current
+	message := world.Message{
+		FirstPersonMessage: fmt.Sprintf("Focusing inward, you do %d damage to yourself.", amount),
+		FirstPerson:        player,
+		ThirdPersonMessage: fmt.Sprintf("Focusing inward, %s self inflicts %d damage.", player.Name, amount),
+	}
+	if err := player.Room.ShowMessage(message); err != nil {
+		log.Fatalf("Problem showing die message: %v", err)
 	}
-	defender.Stun(1)
+	simulate.DoDamage(player, player, amount)
 }
 
-func (b Blitz) Label() string {
-	return "blitz"
+func (d Die) Label() string {
+	return "die"
 }
 
-type Circle struct{}
+// Dispel strips beneficial magic from an opponent, one more effect for each
+// level.
+type Dispel struct{}
 
-func (c Circle) Execute(ctx Context) {
+func (d Dispel) Execute(ctx Context) {
 	attacker := ctx.Player
-	defender := FindTarget(attacker, ctx.Raw)
-	if defender == nil {
-		attacker.Showln("Circle who?")
+	if !CanUseSkill(ctx.World, attacker, d.Label()) {
 		return
 	}
-
-	level := attacker.Skills.Circle.Value()
-	if !CanUseSkill(attacker, c.Label(), level, level) {
-		return
-	}
-
-	attacker.Spirit.Consume(level)
-	attacker.StartAttacking(defender)
-	defender.StartAttacking(attacker)
-	coolDown := buffs.NewCoolDown(12, c.Label())
-	attacker.ApplyCoolDown(&coolDown)
-
-	if c.TargetExpectsCircle(defender) {
-		c.HandleExpectedCircle(attacker, defender)
+	defender := FindTarget(attacker, ctx.Raw)
+	if defender == nil || defender == attacker {
+		attacker.Showln("Dispel who?")
 		return
 	}
-
-	defender.Memory.AddGameEvent(c.Label(), 18)
-	hitDamage := simulate.CalculateHitDamage(attacker, defender)
-	amount := c.CalculateDamage(level, hitDamage.Amount)
-
-	message := world.Message{
-		FirstPersonMessage:  fmt.Sprintf("You circle %s for %d damage.", defender.Name, amount),
-		FirstPerson:         attacker,
-		SecondPersonMessage: fmt.Sprintf("%s circles you for %d damage.", attacker.Name, amount),
-		SecondPerson:        defender,
-		ThirdPersonMessage:  fmt.Sprintf("%s circles %s for %d damage.", attacker.Name, defender.Name, amount),
-	}
-	if err := attacker.Room.ShowMessage(message); err != nil {
-		log.Fatalf("Problem showing cirle message: %v", err)
-	}
-	simulate.DoDamage(attacker, defender, amount)
-	defender.Stun(1)
-}
-
-func (c Circle) CalculateDamage(level int, hitDamage int) int {
-	multiplier := 3.0 + (level / 10.0)
-	return multiplier * hitDamage
-}
-
-func (c Circle) TargetExpectsCircle(target *world.Character) bool {
-	return target.Memory.MostRecent() == c.Label()
+	InitBattleSkill(ctx.World, attacker, defender, d.Label())
+	d.DoDispel(attacker, defender)
 }
 
-func (c Circle) HandleExpectedCircle(attacker *world.Character, defender *world.Character) {
+func (d Dispel) DoDispel(attacker *world.Character, defender *world.Character) {
+	removed := defender.Dispel(attacker.Skills.Level(d.Label()), world.Beneficial, world.Magic)
 	message := world.Message{
-		FirstPersonMessage:  fmt.Sprintf("You try to circle %s.  Clearly expecting it, %s dodges easily.", defender.Name, defender.Name),
 		FirstPerson:         attacker,
-		SecondPersonMessage: fmt.Sprintf("%s tries to circle you.  Expecting it, you dodge easily.", attacker.Name),
+		FirstPersonMessage:  fmt.Sprintf("You unravel the magic around %s.", defender.Name),
 		SecondPerson:        defender,
-		ThirdPersonMessage:  fmt.Sprintf("%s tries to circle %s.  Clearly expecting it, %s dodges easily.", attacker.Name, defender.Name, defender.Name),
+		SecondPersonMessage: fmt.Sprintf("%s unravels the magic around you.", attacker.Name),
+		ThirdPersonMessage:  fmt.Sprintf("%s unravels the magic around %s.", attacker.Name, defender.Name),
+	}
+	if len(removed) == 0 {
+		message.FirstPersonMessage = fmt.Sprintf("You find no magic around %s to unravel.", defender.Name)
+		message.SecondPersonMessage = fmt.Sprintf("%s reaches for the magic around you, but finds none.", attacker.Name)
+		message.ThirdPersonMessage = fmt.Sprintf("%s reaches for the magic around %s, but finds none.", attacker.Name, defender.Name)
 	}
 	if err := attacker.Room.ShowMessage(message); err != nil {
-		log.Fatalf("Problem showing expected circle message: %v", err)
+		log.Fatalf("Problem showing dispel message: %v", err)
 	}
 }
 
-func (c Circle) Label() string {
-	return "circle"
-}
-
-type Die struct{}
-
-func (d Die) Execute(ctx Context) {
-	player := ctx.Player
-	amount := player.Health.Current
-	message := world.Message{
-		FirstPersonMessage: fmt.Sprintf("Focusing inward, you do %d damage to yourself.", amount),
-		FirstPerson:        player,
-		ThirdPersonMessage: fmt.Sprintf("Focusing inward, %s self inflicts %d damage.", player.Name, amount),
-	}
-	if err := player.Room.ShowMessage(message); err != nil {
-		log.Fatalf("Problem showing die message: %v", err)
-	}
-	simulate.DoDamage(player, player, amount)
-}
-
-func (d Die) Label() string {
-	return "die"
+func (d Dispel) Label() string {
+	return "dispel"
 }
 
 type Drop struct{}
@@ -446,10 +357,54 @@ func (d Drop) Label() string {
 	return "drop"
 }
 
+type Follow struct{}
+
+func (f Follow) Execute(ctx Context) {
+	player := ctx.Player
+	parts := strings.SplitN(ctx.Raw, " ", 2)
+	leader := player
+	if len(parts) == 2 {
+		leader = player.Room.GetPlayer(parts[1])
+		if leader == nil {
+			player.Showln("You don't see '%s'.", parts[1])
+			return
+		}
+	}
+
+	if leader == player {
+		if player.Following == nil {
+			player.Showln("You aren't following anyone.")
+			return
+		}
+		player.Showln("You stop following %s.", player.Following.Name)
+		player.Following.Showln("%s stops following you.", player.Name)
+		player.Following = nil
+		return
+	}
+
+	player.Following = leader
+	message := world.Message{
+		FirstPerson:         player,
+		FirstPersonMessage:  fmt.Sprintf("You now follow %s.", leader.Name),
+		SecondPerson:        leader,
+		SecondPersonMessage: fmt.Sprintf("%s now follows you.", player.Name),
+		ThirdPersonMessage:  fmt.Sprintf("%s now follows %s.", player.Name, leader.Name),
+	}
+	player.Room.ShowMessage(message)
+}
+
+func (f Follow) Label() string {
+	return "follow"
+}
+
 type Flee struct{}
 
 func (f Flee) Execute(ctx Context) {
 	player := ctx.Player
+	if player.IsRooted() {
+		player.Showln("You're rooted in place and can't flee!")
+		return
+	}
 	roomUUID := player.Room.Exits.FirstExit()
 	room, ok := ctx.World.Rooms[roomUUID]
 	if !ok || room.IsFull() {
@@ -497,11 +452,13 @@ func (g Gear) Execute(ctx Context) {
 	player.Showln("    [Waist]: %s", g.SafeName(gear.Waist))
 	player.Showln("     [Legs]: %s", g.SafeName(gear.Legs))
 	player.Showln("     [Feet]: %s", g.SafeName(gear.Feet))
-	player.Showln("     Wrist]: %s", g.SafeName(gear.Wrist))
+	player.Showln("    [Wrist]: %s", g.SafeName(gear.Wrist))
 	player.Showln("  [Fingers]: %s", g.SafeName(gear.Fingers))
 	player.Showln(" [Off Hand]: %s", g.SafeName(gear.OffHand))
 	player.Showln("[Main Hand]: %s", g.SafeName(gear.MainHand))
 	player.Showln("")
+	player.Showln("      Armor: %d (%d%% reduction)", gear.Armor(), int(gear.ArmorReduction()*100))
+	player.Showln("")
 }
 
 func (g Gear) Label() string {
@@ -541,19 +498,86 @@ func (g Get) Label() string {
 	return "get"
 }
 
+type Group struct{}
+
+func (g Group) Execute(ctx Context) {
+	player := ctx.Player
+	parts := strings.SplitN(ctx.Raw, " ", 2)
+	if len(parts) == 1 {
+		g.ShowGroup(player)
+		return
+	}
+
+	if player.Group != nil && player.Group.Leader != player {
+		player.Showln("Only the leader can add to the group.")
+		return
+	}
+	member := player.Room.GetPlayer(parts[1])
+	if member == nil || member == player {
+		player.Showln("You don't see '%s'.", parts[1])
+		return
+	}
+	if member.Following != player {
+		player.Showln("%s isn't following you.", member.Name)
+		return
+	}
+	if member.Group != nil {
+		player.Showln("%s is already in a group.", member.Name)
+		return
+	}
+
+	group := player.Group
+	if group == nil {
+		group = world.NewGroup(player)
+	}
+	if err := group.Add(member); err != nil {
+		log.Fatalf("Unable to add %s to group: %v", member.Name, err)
+	}
+	for _, other := range group.Members {
+		if other != member {
+			other.Showln("%s joins the group.", member.Name)
+		}
+	}
+	member.Showln("You join %s's group.", player.Name)
+}
+
+func (g Group) ShowGroup(player *world.Character) {
+	if player.Group == nil {
+		player.Showln("You aren't in a group.")
+		return
+	}
+	player.Showln("%s's group", player.Group.Leader.Name)
+	player.ShowDivider()
+	player.ShowNewline()
+	for _, member := range player.Group.Members {
+		player.Showln("%s <red>%d/%d%s <green>%d/%d%s<reset>",
+			member.Name,
+			member.Health.Current, member.Health.Maximum, symbols.HEART,
+			member.Spirit.Current, member.Spirit.Maximum, symbols.TWELVE_STAR)
+	}
+}
+
+func (g Group) Label() string {
+	return "group"
+}
+
 type Haste struct{}
 
 func (h Haste) Execute(ctx Context) {
 	player := ctx.Player
-	if !player.Skills.Haste.IsAvailable() {
+	if player.IsSilenced() {
+		player.Showln("You can't move with haste while silenced.")
+		return
+	}
+	if player.Skills.Level(h.Label()) <= 0 {
 		player.Showln("You fail to move with haste.")
 		return
 	}
 
 	buff := buffs.NewHaste(player)
 
-	if player.HasBuff(buff.Name()) {
-		player.Unapply(buff.Name())
+	if applied := player.GetBuff(buff.Name()); applied != nil {
+		player.Unapply(applied)
 	} else {
 		player.Apply(buff)
 	}
@@ -573,12 +597,17 @@ func (i Inspect) Execute(ctx Context) {
 		return
 	}
 	index := player.Inventory.IndexOfItem(parts[1])
-	if index == -1 {
-		player.Showln("You don't have a '%s'", parts[1])
+	if index != -1 {
+		player.Showln(player.Inventory.GetItemAtIndex(index).Description())
 		return
 	}
-	item := player.Inventory.GetItemAtIndex(index)
-	player.Showln(item.Description())
+	for _, worn := range player.Gear.Items() {
+		if worn.HasKeyword(parts[1]) {
+			player.Showln(worn.Description())
+			return
+		}
+	}
+	player.Showln("You don't have a '%s'", parts[1])
 }
 
 func (i Inspect) Label() string {
@@ -602,8 +631,7 @@ func (i Inventory) Label() string {
 
 type Invest struct{}
 
-func spendEssence(p *world.Character, amount int) bool {
-	cost := essenceCost(amount)
+func spendEssence(p *world.Character, cost int) bool {
 	if p.Essence < cost {
 		p.Showln("You need %d more essence.", cost-p.Essence)
 		return false
@@ -625,72 +653,39 @@ func (i Invest) Execute(ctx Context) {
 	}
 	keyword := strings.ToLower(parts[1])
 	core := &player.Core
-	skills := &player.Skills
 	switch keyword {
 	case "power":
-		if spendEssence(player, core.Power.Base) {
+		if spendEssence(player, essenceCost(core.Power.Base)) {
 			core.Power.Increment()
 			player.Showln("Power courses through you.")
 		}
 	case "agility":
-		if spendEssence(player, core.Agility.Base) {
+		if spendEssence(player, essenceCost(core.Agility.Base)) {
 			core.Agility.Increment()
 			player.Showln("Balance flows through you.")
 		}
 	case "insight":
-		if spendEssence(player, core.Insight.Base) {
+		if spendEssence(player, essenceCost(core.Insight.Base)) {
 			core.Insight.Increment()
 			player.Showln("The world becomes clearer.")
 		}
 	case "will":
-		if spendEssence(player, core.Will.Base) {
+		if spendEssence(player, essenceCost(core.Will.Base)) {
 			core.Will.Increment()
 			player.Showln("Reality itself warps before you.")
 		}
-	case "evasion":
-		if spendEssence(player, skills.Evasion.Base) {
-			skills.Evasion.Increment()
-			player.Showln("You'll evade more alacrity.")
-		}
-	case "parry":
-		if spendEssence(player, skills.Parry.Base) {
-			skills.Parry.Increment()
-			player.Showln("You'll parry with ease.")
-		}
-	case "barrier":
-		if spendEssence(player, skills.Barrier.Base) {
-			skills.Barrier.Increment()
-			player.Showln("Your mastery of barrier improves.")
-		}
-	case "bash":
-		if spendEssence(player, skills.Bash.Base) {
-			skills.Bash.Increment()
-			player.Showln("Your mastery of bash improves.")
-		}
-	case "bleed":
-		if spendEssence(player, skills.Bleed.Base) {
-			skills.Bleed.Increment()
-			player.Showln("Your mastery of bleed improves.")
-		}
-	case "blitz":
-		if spendEssence(player, skills.Blitz.Base) {
-			skills.Blitz.Increment()
-			player.Showln("Your mastery of blitz improves.")
-		}
-	case "circle":
-		if spendEssence(player, skills.Circle.Base) {
-			skills.Circle.Increment()
-			player.Showln("Your mastery of circle improves.")
+	default:
+		definition, ok := ctx.World.Skills[keyword]
+		if !ok {
+			player.Showln("Invest what?")
+			return
 		}
-	case "haste":
-		if spendEssence(player, skills.Haste.Base) {
-			skills.Haste.Increment()
-			player.Showln("Your mastery of haste improves.")
+		skill := player.Skills.Get(keyword)
+		if spendEssence(player, definition.Cost.At(skill.Base)) {
+			skill.Increment()
+			player.Showln(definition.Invest)
 		}
-	default:
-		player.Showln("Invest what?")
 	}
-
 }
 
 func (i Invest) Label() string {
@@ -764,6 +759,78 @@ func (q Quest) Label() string {
 	return "quest"
 }
 
+// Rescue draws an opponent's attacks away from an ally.
+type Rescue struct{}
+
+func (r Rescue) Execute(ctx Context) {
+	player := ctx.Player
+	if !CanUseSkill(ctx.World, player, r.Label()) {
+		return
+	}
+	parts := strings.SplitN(ctx.Raw, " ", 2)
+	if len(parts) == 1 {
+		player.Showln("Rescue who?")
+		return
+	}
+	ally := player.Room.GetPlayer(parts[1])
+	if ally == nil || ally == player {
+		player.Showln("You don't see '%s'.", parts[1])
+		return
+	}
+	opponent := r.FindOpponent(ally)
+	if opponent == nil {
+		player.Showln("No one is attacking %s.", ally.Name)
+		return
+	}
+
+	UseSkill(ctx.World, player, r.Label())
+	if ctx.World.Random.Float64() > r.Chance(player) {
+		message := world.Message{
+			FirstPerson:         player,
+			FirstPersonMessage:  fmt.Sprintf("You fail to rescue %s.", ally.Name),
+			SecondPerson:        ally,
+			SecondPersonMessage: fmt.Sprintf("%s fails to rescue you.", player.Name),
+			ThirdPersonMessage:  fmt.Sprintf("%s fails to rescue %s.", player.Name, ally.Name),
+		}
+		player.Room.ShowMessage(message)
+		return
+	}
+	r.DoRescue(player, ally, opponent)
+}
+
+// FindOpponent returns someone in the room whose attacks are aimed at the
+// ally.
+func (r Rescue) FindOpponent(ally *world.Character) *world.Character {
+	for _, candidate := range ally.Room.Players {
+		if candidate.ImmediateDefender() == ally {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func (r Rescue) Chance(player *world.Character) float64 {
+	return float64(player.Skills.Level(r.Label()))*.05 + float64(player.Core.Agility.Value())*.01
+}
+
+func (r Rescue) DoRescue(player *world.Character, ally *world.Character, opponent *world.Character) {
+	opponent.Threat.Taunt(player)
+	opponent.AttackFirst(player)
+	player.AttackFirst(opponent)
+	message := world.Message{
+		FirstPerson:         player,
+		FirstPersonMessage:  fmt.Sprintf("You rescue %s from %s!", ally.Name, opponent.Name),
+		SecondPerson:        ally,
+		SecondPersonMessage: fmt.Sprintf("%s rescues you from %s!", player.Name, opponent.Name),
+		ThirdPersonMessage:  fmt.Sprintf("%s rescues %s from %s!", player.Name, ally.Name, opponent.Name),
+	}
+	player.Room.ShowMessage(message)
+}
+
+func (r Rescue) Label() string {
+	return "rescue"
+}
+
 type Remove struct{}
 
 func (r Remove) Execute(ctx Context) {
@@ -790,7 +857,12 @@ func (r Remove) Label() string {
 type Save struct{}
 
 func (s Save) Execute(ctx Context) {
-	savePlayers(ctx.World.Players)
+	if err := ctx.Store.SaveCharacter(ctx.Player); err != nil {
+		log.Printf("Unable to save player %s: %v", ctx.Player.Name, err)
+		ctx.Player.Showln("Unable to save.")
+		return
+	}
+	ctx.Player.Showln("Saved.")
 }
 
 func (s Save) Label() string {
@@ -808,10 +880,11 @@ func (sc Score) Execute(ctx Context) {
 	p.Showln(strings.Join(parts, "   "))
 	p.Showln("")
 	p.Showln(p.Core.Describe())
+	p.Showln(p.Resistances.Describe())
 	p.Showln("")
 	p.Showln("<yellow>%s<reset> Essence %d", symbols.FIVE_STAR, p.Essence)
 	p.Showln("")
-	p.Showln(p.Skills.Describe())
+	p.Showln(p.Skills.Describe(ctx.World.Skills))
 	p.Showln("")
 }
 
@@ -819,65 +892,155 @@ func (sc Score) Label() string {
 	return "score"
 }
 
-type StunLocked struct{}
-
-func (s StunLocked) Execute(ctx Context) {
-	ctx.Player.Showln("You are too stunned.")
-}
-
-func (s StunLocked) Label() string {
-	return ""
-}
+// ShootCoolDown is a battle round in ticks, so a shot takes as long as an
+// attack.
+const ShootCoolDown = 3
 
-type Sweep struct{}
+// Shoot fires ammunition from a ranged weapon at a target in an adjacent
+// room.
+type Shoot struct{}
 
-func (s Sweep) Execute(ctx Context) {
+func (s Shoot) Execute(ctx Context) {
 	attacker := ctx.Player
-	level := attacker.Skills.Sweep.Value()
-	cost := level
-	if !CanUseSkill(attacker, s.Label(), level, cost) {
+	parts := strings.SplitN(ctx.Raw, " ", 3)
+	if len(parts) < 3 {
+		attacker.Showln("Shoot who, and in which direction?")
 		return
 	}
-
 	if !IsWieldingRange(attacker) {
-		attacker.Showln("You can't sweep without a ranged weapon.")
+		attacker.Showln("You can't shoot without a ranged weapon.")
+		return
+	}
+	if attacker.IsFighting() {
+		attacker.Showln("You can't take aim while fighting.")
+		return
+	}
+	if attacker.OnCoolDown(s.Label()) {
+		attacker.Showln("You need a moment before you can shoot again.")
 		return
 	}
 
-	defender := FindTarget(attacker, ctx.Raw)
+	name, exit, ok := attacker.Room.Exits.Find(parts[1])
+	if !ok {
+		attacker.Showln("You can't shoot %s.", parts[1])
+		return
+	}
+	if !exit.IsPassable() {
+		attacker.Showln("The %s is closed.", exit.Door.Name)
+		return
+	}
+	room, ok := ctx.World.Rooms[exit.RoomUUID]
+	if !ok {
+		attacker.Showln("You can't see anything %s.", name)
+		return
+	}
+	defender := room.GetPlayer(parts[2])
 	if defender == nil {
-		attacker.Showln("Sweep who?")
+		attacker.Showln("You don't see '%s' %s.", parts[2], name)
 		return
 	}
 
-	coolDown := level
-	InitBattleSkill(attacker, defender, cost, s.Label(), coolDown)
+	ammunition := attacker.TakeAmmunition()
+	if ammunition == nil {
+		attacker.Showln("You have nothing to shoot.")
+		return
+	}
+	ctx.World.Items.Unspawn(ammunition)
+	coolDown := buffs.NewCoolDown(ShootCoolDown, s.Label())
+	attacker.ApplyCoolDown(&coolDown)
 
-	s.DoSweep(attacker, level)
+	if !s.DoShoot(ctx.World, attacker, defender, name, ammunition) {
+		s.React(ctx.World, attacker, defender)
+	}
 }
 
-func (s Sweep) DoSweep(attacker *world.Character, level int) {
-	for _, defender := range attacker.Attacking {
-		defender.Memory.AddGameEvent(s.Label(), 10)
-		hitDamage := simulate.CalculateHitDamage(attacker, defender)
-		amount := hitDamage.Amount
+// DoShoot hits a defender in another room and returns whether they died.
+func (s Shoot) DoShoot(World *world.World, attacker *world.Character, defender *world.Character, direction string, ammunition item.Item) bool {
+	hitDamage := simulate.CalculateHitDamage(World.Random, attacker, defender)
+	amount := hitDamage.Amount
+	projectile := strings.ToLower(ammunition.Name())
+	from := "somewhere"
+	if name, ok := defender.Room.Exits.Toward(attacker.Room.UUID); ok {
+		from = "the " + name
+	}
 
-		message := world.Message{
-			FirstPersonMessage:  fmt.Sprintf("You sweep %s for %d damage.", defender.Name, amount),
-			FirstPerson:         attacker,
-			SecondPersonMessage: fmt.Sprintf("%s sweeps you for %d damage.", attacker.Name, amount),
-			SecondPerson:        defender,
-			ThirdPersonMessage:  fmt.Sprintf("%s sweeps %s for %d damage.", attacker.Name, defender.Name, amount),
+	message := world.Message{
+		FirstPerson:        attacker,
+		FirstPersonMessage: fmt.Sprintf("You shoot %s %s at %s for %d damage.", projectile, direction, defender.Name, amount),
+		ThirdPersonMessage: fmt.Sprintf("%s shoots %s %s.", attacker.Name, projectile, direction),
+	}
+	if err := attacker.Room.ShowMessage(message); err != nil {
+		log.Fatalf("Problem showing shoot message: %v", err)
+	}
+	message = world.Message{
+		SecondPerson:        defender,
+		SecondPersonMessage: fmt.Sprintf("%s's %s flies in from %s and hits you for %d damage.", attacker.Name, projectile, from, amount),
+		ThirdPersonMessage:  fmt.Sprintf("%s's %s flies in from %s and hits %s.", attacker.Name, projectile, from, defender.Name),
+	}
+	if err := defender.Room.ShowMessage(message); err != nil {
+		log.Fatalf("Problem showing shoot message: %v", err)
+	}
+	return simulate.DoDamage(attacker, defender, amount)
+}
+
+// React has a mobile that was shot close the distance when aggressive and
+// flee otherwise.  Mobiles already fighting stand their ground.
+func (s Shoot) React(w *world.World, attacker *world.Character, defender *world.Character) {
+	if defender.IsPlayer || defender.IsFighting() || defender.IsStunned() {
+		return
+	}
+	origin := defender.Room
+	if defender.IsAggressive {
+		name, ok := origin.Exits.Toward(attacker.Room.UUID)
+		if !ok {
+			return
 		}
-		if err := attacker.Room.ShowMessage(message); err != nil {
-			log.Fatalf("Problem showing sweep message: %v", err)
+		if _, err := w.MoveCharacter(defender, name); err != nil {
+			return
 		}
-		simulate.DoDamage(attacker, defender, amount)
+		origin.ShowMessage(world.Message{
+			ThirdPersonMessage: fmt.Sprintf("%s charges %s.", defender.Name, name),
+		})
+		defender.StartAttacking(attacker)
+		attacker.StartAttacking(defender)
+		defender.Room.ShowMessage(world.Message{
+			FirstPerson:         defender,
+			SecondPerson:        attacker,
+			SecondPersonMessage: fmt.Sprintf("%s charges in and attacks you!", defender.Name),
+			ThirdPersonMessage:  fmt.Sprintf("%s charges in and attacks %s!", defender.Name, attacker.Name),
+		})
+		return
+	}
+	for _, name := range origin.Exits.Names() {
+		if origin.Exits[name].RoomUUID == attacker.Room.UUID {
+			continue
+		}
+		if _, err := w.MoveCharacter(defender, name); err != nil {
+			continue
+		}
+		origin.ShowMessage(world.Message{
+			ThirdPersonMessage: fmt.Sprintf("%s flees %s.", defender.Name, name),
+		})
+		defender.Room.ShowMessage(world.Message{
+			FirstPerson:        defender,
+			ThirdPersonMessage: fmt.Sprintf("%s arrives in a panic.", defender.Name),
+		})
+		return
 	}
 }
 
-func (s Sweep) Label() string {
-	return "sweep"
+func (s Shoot) Label() string {
+	return "shoot"
+}
+
+type StunLocked struct{}
+
+func (s StunLocked) Execute(ctx Context) {
+	ctx.Player.Showln("You are too stunned.")
+}
+
+func (s StunLocked) Label() string {
+	return ""
 }
 
 type Typo struct{}
@@ -900,116 +1063,349 @@ func (n Noop) Label() string {
 	return ""
 }
 
-func MovePlayer(ctx Context, roomUUID string, direction string) {
+func MovePlayer(ctx Context, direction string) {
 	player := ctx.Player
 	if player.IsFighting() {
 		player.Showln("You can't go %s while fighting.", direction)
 		return
 	}
 
-	if roomUUID == "" {
+	origin := player.Room
+	name, err := ctx.World.MoveCharacter(player, direction)
+	switch err {
+	case nil:
+	case world.NoExit:
 		player.Showln("You can't go %s", direction)
 		return
+	case world.DoorClosed:
+		player.Showln("The %s is closed.", player.Room.Exits[name].Door.Name)
+		return
+	case world.UnknownRoom:
+		player.Showln("An unseen force prevents you from going %s", name)
+		return
+	case world.Rooted:
+		player.Showln("You're rooted in place.")
+		return
+	default:
+		player.Showln("You're unable to go %s", name)
+		return
+	}
+	if world.IsDirection(name) {
+		player.Showln("You go %s", name)
+	} else {
+		player.Showln("You go through the %s", name)
 	}
+	Look{}.Execute(ctx)
 
-	room, ok := ctx.World.Rooms[roomUUID]
-	if !ok {
-		player.Showln("An unseen force prevents you from going %s", direction)
-		return
+	followers := make([]*world.Character, 0)
+	for _, candidate := range origin.Players {
+		if candidate.Following == player {
+			followers = append(followers, candidate)
+		}
+	}
+	for _, follower := range followers {
+		follower.Showln("You follow %s.", player.Name)
+		MovePlayer(Context{World: ctx.World, Store: ctx.Store, Player: follower, Help: ctx.Help}, name)
 	}
+}
 
-	oldRoom := player.Room
-	if err := player.Room.Exit(player); err != nil {
-		player.Showln("You're unable to leave %s", player.Room.Name())
+// Move is a command for each standard direction so that directions keep
+// their single letter aliases.
+type Move struct {
+	Direction string
+}
+
+func (m Move) Execute(ctx Context) {
+	MovePlayer(ctx, m.Direction)
+}
+
+func (m Move) Label() string {
+	return m.Direction
+}
+
+type Enter struct{}
+
+func (e Enter) Execute(ctx Context) {
+	parts := strings.SplitN(ctx.Raw, " ", 2)
+	if len(parts) == 1 {
+		ctx.Player.Showln("Enter what?")
 		return
 	}
+	MovePlayer(ctx, parts[1])
+}
 
-	if err := room.Enter(player); err != nil {
-		oldRoom.Enter(player)
-		player.Showln("You're unable to enter %s", room.Name())
-		player.Room = oldRoom
+func (e Enter) Label() string {
+	return "enter"
+}
+
+// findDoor finds the door named by the command's argument, telling the
+// player when there isn't one.
+func findDoor(ctx Context, verb string) (*world.Exit, bool) {
+	player := ctx.Player
+	parts := strings.SplitN(ctx.Raw, " ", 2)
+	if len(parts) == 1 {
+		player.Showln("%s what?", strings.ToUpper(verb[:1])+verb[1:])
+		return nil, false
+	}
+	_, exit, ok := player.Room.Exits.FindDoor(parts[1])
+	if !ok {
+		player.Showln("You don't see a door '%s'.", parts[1])
+		return nil, false
+	}
+	return exit, true
+}
+
+func showDoor(ctx Context, exit *world.Exit, verb string) {
+	player := ctx.Player
+	door := exit.Door
+	message := world.Message{
+		FirstPerson:        player,
+		FirstPersonMessage: fmt.Sprintf("You %s the %s.", verb, door.Name),
+		ThirdPersonMessage: fmt.Sprintf("%s %ss the %s.", player.Name, verb, door.Name),
+	}
+	player.Room.ShowMessage(message)
+	if other, ok := ctx.World.Rooms[exit.RoomUUID]; ok && other != player.Room {
+		other.ShowMessage(world.Message{
+			ThirdPersonMessage: fmt.Sprintf("The %s %ss from the other side.", door.Name, verb),
+		})
+	}
+}
+
+type Open struct{}
+
+func (o Open) Execute(ctx Context) {
+	exit, ok := findDoor(ctx, o.Label())
+	if !ok {
 		return
 	}
-	player.Room = room
-	player.Showln("You go %s", direction)
-	Look{}.Execute(ctx)
+	switch exit.Door.Open() {
+	case world.DoorLocked:
+		ctx.Player.Showln("The %s is locked.", exit.Door.Name)
+	case world.DoorOpen:
+		ctx.Player.Showln("The %s is already open.", exit.Door.Name)
+	case nil:
+		showDoor(ctx, exit, o.Label())
+	}
 }
 
-type East struct{}
+func (o Open) Label() string {
+	return "open"
+}
+
+type Close struct{}
 
-func (e East) Execute(ctx Context) {
-	roomUUID := ctx.Player.Room.Exits.East
-	MovePlayer(ctx, roomUUID, e.Label())
+func (c Close) Execute(ctx Context) {
+	exit, ok := findDoor(ctx, c.Label())
+	if !ok {
+		return
+	}
+	switch exit.Door.Close() {
+	case world.DoorClosed:
+		ctx.Player.Showln("The %s is already closed.", exit.Door.Name)
+	case nil:
+		showDoor(ctx, exit, c.Label())
+	}
 }
 
-func (e East) Label() string {
-	return "east"
+func (c Close) Label() string {
+	return "close"
 }
 
-type North struct{}
+type Lock struct{}
 
-func (n North) Execute(ctx Context) {
-	roomUUID := ctx.Player.Room.Exits.North
-	MovePlayer(ctx, roomUUID, n.Label())
+func (l Lock) Execute(ctx Context) {
+	exit, ok := findDoor(ctx, l.Label())
+	if !ok {
+		return
+	}
+	door := exit.Door
+	if door.KeyUUID != "" && !ctx.Player.HasItem(door.KeyUUID) {
+		ctx.Player.Showln("You don't have the key to the %s.", door.Name)
+		return
+	}
+	switch door.Lock() {
+	case world.DoorHasNoLock:
+		ctx.Player.Showln("The %s has no lock.", door.Name)
+	case world.DoorLocked:
+		ctx.Player.Showln("The %s is already locked.", door.Name)
+	case world.DoorOpen:
+		ctx.Player.Showln("You need to close the %s first.", door.Name)
+	case nil:
+		showDoor(ctx, exit, l.Label())
+	}
 }
 
-func (n North) Label() string {
-	return "north"
+func (l Lock) Label() string {
+	return "lock"
 }
 
-type South struct{}
+// Ungroup leaves the group, or with a name has the leader remove a member.
+type Ungroup struct{}
+
+func (u Ungroup) Execute(ctx Context) {
+	player := ctx.Player
+	group := player.Group
+	if group == nil {
+		player.Showln("You aren't in a group.")
+		return
+	}
+
+	member := player
+	parts := strings.SplitN(ctx.Raw, " ", 2)
+	if len(parts) == 2 {
+		if group.Leader != player {
+			player.Showln("Only the leader can remove from the group.")
+			return
+		}
+		member = group.Find(parts[1])
+		if member == nil {
+			player.Showln("'%s' isn't in your group.", parts[1])
+			return
+		}
+	}
 
-func (s South) Execute(ctx Context) {
-	roomUUID := ctx.Player.Room.Exits.South
-	MovePlayer(ctx, roomUUID, s.Label())
+	members := append([]*world.Character{}, group.Members...)
+	if err := group.Remove(member); err != nil {
+		log.Fatalf("Unable to remove %s from group: %v", member.Name, err)
+	}
+	for _, other := range members {
+		switch {
+		case member == group.Leader:
+			if other != member {
+				other.Showln("%s disbands the group.", member.Name)
+			}
+		case other == member && member == player:
+			other.Showln("You leave the group.")
+		case other == member:
+			other.Showln("%s removes you from the group.", player.Name)
+		default:
+			other.Showln("%s leaves the group.", member.Name)
+		}
+	}
+	if member == group.Leader {
+		player.Showln("You disband the group.")
+	}
 }
 
-func (s South) Label() string {
-	return "south"
+func (u Ungroup) Label() string {
+	return "ungroup"
 }
 
-type West struct{}
+type Unlock struct{}
 
-func (w West) Execute(ctx Context) {
-	roomUUID := ctx.Player.Room.Exits.West
-	MovePlayer(ctx, roomUUID, w.Label())
+func (u Unlock) Execute(ctx Context) {
+	exit, ok := findDoor(ctx, u.Label())
+	if !ok {
+		return
+	}
+	door := exit.Door
+	if door.KeyUUID != "" && !ctx.Player.HasItem(door.KeyUUID) {
+		ctx.Player.Showln("You don't have the key to the %s.", door.Name)
+		return
+	}
+	switch door.Unlock() {
+	case world.DoorHasNoLock:
+		ctx.Player.Showln("The %s has no lock.", door.Name)
+	case world.DoorUnlocked:
+		ctx.Player.Showln("The %s isn't locked.", door.Name)
+	case nil:
+		showDoor(ctx, exit, u.Label())
+	}
 }
 
-func (w West) Label() string {
-	return "west"
+func (u Unlock) Label() string {
+	return "unlock"
 }
 
-type Wear struct{}
+// hands maps the words a player can use for a hand to its gear slot.
+var hands = map[string]string{
+	"main":     item.MainHand,
+	"mainhand": item.MainHand,
+	"off":      item.OffHand,
+	"offhand":  item.OffHand,
+}
 
-func (wr Wear) Execute(ctx Context) {
+// equip wears or wields an item from the inventory.  The last word can
+// name a hand, like "wield dagger off".
+func equip(ctx Context, verb string, weaponsOnly bool) {
 	player := ctx.Player
 	parts := strings.SplitN(ctx.Raw, " ", 2)
 	if len(parts) == 1 {
-		player.Showln("Wear what?")
+		player.Showln("%s what?", strings.ToUpper(verb[:1])+verb[1:])
 		return
 	}
-	index := player.Inventory.IndexOfItem(parts[1])
+	args := strings.Fields(parts[1])
+	slot := ""
+	if len(args) > 1 {
+		if hand, ok := hands[args[len(args)-1]]; ok {
+			slot = hand
+			args = args[:len(args)-1]
+		}
+	}
+	keyword := strings.Join(args, " ")
+	index := player.Inventory.IndexOfItem(keyword)
 	if index == -1 {
-		player.Showln("You don't have a '%s'", parts[1])
+		player.Showln("You don't have a '%s'", keyword)
 		return
 	}
-	i := player.Inventory.RemItemAtIndex(index)
-	previous, err := player.Gear.Equip(i)
-	if err != nil {
-		player.Showln("You can't wear %s.", i.Name())
-		player.Inventory.AddItem(i)
-	} else {
-		player.Showln("You wear %s", i.Name())
+	i := player.Inventory.GetItemAtIndex(index)
+	if _, ok := i.(*item.Weapon); weaponsOnly && !ok {
+		player.Showln("You can't %s %s.", verb, i.Name())
+		return
 	}
+	if slot == "" {
+		slot = item.SlotFor(i)
+	}
+	previous, err := player.Gear.EquipIn(i, slot)
+	switch err {
+	case nil:
+	case item.TwoHandedOffHand:
+		player.Showln("%s needs both hands.", i.Name())
+		return
+	case item.OffHandBlocked:
+		player.Showln("Your two handed weapon needs your off hand.")
+		return
+	case item.OffHandOccupied:
+		player.Showln("You need a free off hand for %s.", i.Name())
+		return
+	default:
+		player.Showln("You can't %s %s.", verb, i.Name())
+		return
+	}
+	player.Inventory.RemItemAtIndex(index)
 	if !item.IsNil(previous) {
 		player.Inventory.AddItem(previous)
 	}
+	switch slot {
+	case item.MainHand:
+		player.Showln("You %s %s in your main hand.", verb, i.Name())
+	case item.OffHand:
+		player.Showln("You %s %s in your off hand.", verb, i.Name())
+	default:
+		player.Showln("You %s %s", verb, i.Name())
+	}
+}
+
+type Wear struct{}
+
+func (wr Wear) Execute(ctx Context) {
+	equip(ctx, wr.Label(), false)
 }
 
 func (wr Wear) Label() string {
 	return "wear"
 }
 
+type Wield struct{}
+
+func (wi Wield) Execute(ctx Context) {
+	equip(ctx, wi.Label(), true)
+}
+
+func (wi Wield) Label() string {
+	return "wield"
+}
+
 type Help struct{}
 
 func (h Help) Execute(ctx Context) {
@@ -1078,39 +1474,66 @@ var commands = buildCommands()
 func buildCommands() map[string]Executor {
 	commands := []Executor{
 		Anchor{},
+		Assist{},
 		Attack{},
 		Affect{},
-		Backstab{},
+		Technique{Name: "backstab"},
 		Barrier{},
-		Bash{},
-		Bleed{},
-		Blitz{},
-		Circle{},
+		Technique{Name: "bash"},
+		Technique{Name: "bleed"},
+		Technique{Name: "blitz"},
+		Cleanse{},
+		// Circle keeps the "c" alias
+		Close{},
+		Technique{Name: "circle"},
+		// Die keeps the "di" alias
+		Dispel{},
 		Die{},
 		Drop{},
+		Enter{},
+		// Flee keeps the "f" alias
+		Follow{},
 		Flee{},
 		Gear{},
+		// Get keeps the "g" alias
+		Group{},
 		Get{},
 		Haste{},
 		Help{},
 		Inspect{},
 		Inventory{},
 		Invest{},
+		Lock{},
 		Look{},
 		Noop{},
+		Open{},
 		Quest{},
+		// Remove keeps the "r" alias
+		Rescue{},
 		Remove{},
 		Save{},
 		Score{},
-		Sweep{},
+		Shoot{},
+		Technique{Name: "sweep"},
 		Typo{},
+		Ungroup{},
+		Unlock{},
 		Wear{},
-
-		// Direction commands should have alias priority
-		East{},
-		North{},
-		South{},
-		West{},
+		Wield{},
+
+		// Direction commands should have alias priority, with the
+		// cardinal directions after the diagonals that share a prefix.
+		// Drop and sweep give up "d" and "sw" to down and southwest.
+		Move{Direction: world.Northeast},
+		Move{Direction: world.Northwest},
+		Move{Direction: world.Southeast},
+		Move{Direction: world.Southwest},
+		Move{Direction: world.Up},
+		Move{Direction: world.Down},
+		Move{Direction: world.East},
+		Move{Direction: world.North},
+		Move{Direction: world.South},
+		Move{Direction: world.West},
 	}
 	aliases := make(map[string]Executor)
 	for _, command := range commands {
@@ -1120,6 +1543,10 @@ func buildCommands() map[string]Executor {
 		}
 	}
 	aliases[Noop{}.Label()] = Noop{}
+	aliases["ne"] = Move{Direction: world.Northeast}
+	aliases["nw"] = Move{Direction: world.Northwest}
+	aliases["se"] = Move{Direction: world.Southeast}
+	aliases["sw"] = Move{Direction: world.Southwest}
 	return aliases
 }
 
@@ -1130,6 +1557,12 @@ func determineCommand(raw string, ctx Context) Executor {
 	rawCmd := strings.SplitN(raw, " ", 2)[0]
 	command, ok := commands[rawCmd]
 	if !ok {
+		// Custom exits, like a portal, can be used by name.
+		if ctx.Player.Room != nil {
+			if _, found := ctx.Player.Room.Exits[rawCmd]; found {
+				return Move{Direction: rawCmd}
+			}
+		}
 		return commands[Typo{}.Label()]
 	}
 
@@ -1141,5 +1574,8 @@ func determinePlayer(name string, w *world.World) (*world.Character, error) {
 	if !found {
 		return nil, errors.New("player not found")
 	}
+	if player.Session != nil {
+		return nil, errors.New("player already connected")
+	}
 	return player, nil
 }
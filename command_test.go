@@ -1,6 +1,8 @@
 package main
 
 import (
+	"github.com/michaelvmata/path/buffs"
+	item "github.com/michaelvmata/path/items"
 	"github.com/michaelvmata/path/world"
 	"log"
 	"testing"
@@ -14,13 +16,38 @@ func TestDetermineCommand(t *testing.T) {
 	c.Execute(ctx)
 }
 
+// TestCommandAliases pins the short forms that directions took over from
+// other commands.
+func TestCommandAliases(t *testing.T) {
+	aliases := buildCommands()
+	expected := map[string]string{
+		"d":   world.Down,
+		"dr":  Drop{}.Label(),
+		"u":   world.Up,
+		"n":   world.North,
+		"ne":  world.Northeast,
+		"nw":  world.Northwest,
+		"s":   world.South,
+		"se":  world.Southeast,
+		"sw":  world.Southwest,
+		"swe": "sweep",
+		"e":   world.East,
+		"w":   world.West,
+	}
+	for alias, label := range expected {
+		if command := aliases[alias]; command == nil || command.Label() != label {
+			t.Fatalf("Alias %s expected(%s) actual(%v)", alias, label, command)
+		}
+	}
+}
+
 func TestBashCommand(t *testing.T) {
 	world := build("data/areas")
 	world.SpawnMobiles()
 
 	// Missing target
 	ctx := Context{World: world, Player: world.Players["gaigen"], Raw: "bash target"}
-	b := Bash{}
+	b := Technique{Name: "bash"}
 	b.Execute(ctx)
 
 	// No taret
@@ -37,12 +64,12 @@ func TestHasteCommand(t *testing.T) {
 	ctx := Context{Player: player}
 	h := Haste{}
 	h.Execute(ctx)
-	if len(player.Buffs) > 1 || player.Skills.Haste.IsAvailable() {
+	if len(player.Buffs) > 1 || player.Skills.Get(Haste{}.Label()).IsAvailable() {
 		t.Fatalf("Haste command applied without investing.")
 	}
-	player.Skills.Haste.Increment()
+	player.Skills.Get(Haste{}.Label()).Increment()
 	h.Execute(ctx)
-	if len(player.Buffs) < 1 || !player.Skills.Haste.IsAvailable() {
+	if len(player.Buffs) < 1 || !player.Skills.Get(Haste{}.Label()).IsAvailable() {
 		t.Fatalf("Haste command didn't apply haste.")
 	}
 	h.Execute(ctx)
@@ -67,3 +94,267 @@ func TestInvest(t *testing.T) {
 		}
 	}
 }
+
+// gaigenInHallway builds the world with gaigen moved to the hallway, where
+// the doors and exits the command tests need are.
+func gaigenInHallway(t *testing.T) (*world.World, *world.Character) {
+	t.Helper()
+	w := build("data/areas")
+	player := w.Players["gaigen"]
+	hallway := w.Rooms["988b8155b67a41dba313f057f99d760e"]
+	player.Room.Exit(player)
+	if err := hallway.Enter(player); err != nil {
+		t.Fatalf("Unable to enter the hallway %v", err)
+	}
+	player.Room = hallway
+	return w, player
+}
+
+func TestDoorCommands(t *testing.T) {
+	w, player := gaigenInHallway(t)
+	hallway := player.Room
+	door := hallway.Exits[world.East].Door
+	if door != w.Rooms["bcab82c547df4d51a2247ccc7575789d"].Exits[world.West].Door {
+		t.Fatalf("Door not shared between rooms")
+	}
+
+	ctx := Context{World: w, Player: player, Raw: "close door"}
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if !door.Closed {
+		t.Fatalf("Door not closed")
+	}
+	ctx.Raw = "east"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if player.Room != hallway {
+		t.Fatalf("Player walked through a closed door")
+	}
+
+	door.KeyUUID = "Key UUID"
+	ctx.Raw = "lock east"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if door.Locked {
+		t.Fatalf("Door locked without a key")
+	}
+
+	ctx.Raw = "open east"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	ctx.Raw = "e"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if player.Room == hallway {
+		t.Fatalf("Player unable to walk through an open door")
+	}
+}
+
+func TestWield(t *testing.T) {
+	player := world.NewPlayer("Test UUID", "Test Handle")
+	ctx := Context{Player: player}
+	sword := item.NewWeapon("Sword UUID", "test sword", []string{"sword"}, "", item.Slash, []string{item.Blade})
+	dagger := item.NewWeapon("Dagger UUID", "test dagger", []string{"dagger"}, "", item.Pierce, []string{item.Blade})
+	player.Receive(sword)
+	player.Receive(dagger)
+
+	ctx.Raw = "wield sword"
+	Wield{}.Execute(ctx)
+	ctx.Raw = "wield dagger off"
+	Wield{}.Execute(ctx)
+	if player.Gear.MainHand != sword || player.Gear.OffHandWeapon() != dagger {
+		t.Fatalf("Unable to dual wield")
+	}
+	if len(player.Inventory.Items) != 0 {
+		t.Fatalf("Wielded weapons still in inventory")
+	}
+}
+
+func TestShoot(t *testing.T) {
+	w, player := gaigenInHallway(t)
+	w.SpawnMobiles()
+	hallway := player.Room
+	player.Gear = item.NewGear()
+	player.Inventory = item.NewContainer(10)
+	bow := item.NewWeapon("Bow UUID", "test bow", []string{"bow"}, "", item.Pierce, []string{item.Ranged, item.TwoHanded})
+	bow.MinimumDamage = 1
+	bow.MaximumDamage = 2
+	player.Gear.Equip(bow)
+	player.Receive(item.NewItem("Arrow UUID", "Arrow", []string{"arrow"}, "", item.AmmunitionType))
+	player.Receive(item.NewItem("Arrow UUID", "Arrow", []string{"arrow"}, "", item.AmmunitionType))
+
+	room := w.Rooms["bcab82c547df4d51a2247ccc7575789d"]
+	dummy := room.GetPlayer("combat")
+	dummy.Health.Current = 1000
+	ctx := Context{World: w, Player: player, Raw: "shoot east combat"}
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if dummy.Health.Current == 1000 {
+		t.Fatalf("Shot missed the dummy")
+	}
+	if len(player.Inventory.Items) != 1 {
+		t.Fatalf("Shot didn't consume ammunition")
+	}
+	if dummy.Room != room || dummy.IsFighting() {
+		t.Fatalf("Dummy fled without an exit")
+	}
+	health := dummy.Health.Current
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if dummy.Health.Current != health || len(player.Inventory.Items) != 1 {
+		t.Fatalf("Shot twice in the same round")
+	}
+
+	player.CoolDowns = nil
+	dummy.IsAggressive = true
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if dummy.Room != hallway || !dummy.IsAttacking(player) || !player.IsAttacking(dummy) {
+		t.Fatalf("Aggressive dummy didn't close the distance")
+	}
+	player.Receive(item.NewItem("Arrow UUID", "Arrow", []string{"arrow"}, "", item.AmmunitionType))
+	ctx.Raw = "shoot west spiral"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if len(player.Inventory.Items) != 1 {
+		t.Fatalf("Shot while fighting")
+	}
+}
+
+func TestGroupCommands(t *testing.T) {
+	w, player := gaigenInHallway(t)
+	hallway := player.Room
+	follower := world.NewPlayer("Follower UUID", "Follower")
+	hallway.Enter(follower)
+	follower.Room = hallway
+
+	ctx := Context{World: w, Player: player, Raw: "group follower"}
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if player.Group != nil {
+		t.Fatalf("Grouped with someone not following")
+	}
+
+	followerCtx := Context{World: w, Player: follower, Raw: "follow gaigen"}
+	determineCommand(followerCtx.Raw, followerCtx).Execute(followerCtx)
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if player.Group == nil || player.Group != follower.Group {
+		t.Fatalf("Follower not grouped")
+	}
+
+	ctx.Raw = "west"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if player.Room == hallway || follower.Room != player.Room {
+		t.Fatalf("Follower didn't follow")
+	}
+
+	followerCtx.Raw = "ungroup"
+	determineCommand(followerCtx.Raw, followerCtx).Execute(followerCtx)
+	if player.Group != nil || follower.Group != nil {
+		t.Fatalf("Group of one not disbanded")
+	}
+	followerCtx.Raw = "follow"
+	determineCommand(followerCtx.Raw, followerCtx).Execute(followerCtx)
+	if follower.Following != nil {
+		t.Fatalf("Follower didn't stop following")
+	}
+}
+
+func TestAssistRescue(t *testing.T) {
+	w, player := gaigenInHallway(t)
+	hallway := player.Room
+	ally := world.NewPlayer("Ally UUID", "Ally")
+	brute := world.NewPlayer("Brute UUID", "Brute")
+	for _, c := range []*world.Character{ally, brute} {
+		hallway.Enter(c)
+		c.Room = hallway
+	}
+
+	ctx := Context{World: w, Player: player, Raw: "assist ally"}
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if player.IsFighting() {
+		t.Fatalf("Assisted an ally who isn't fighting")
+	}
+	brute.StartAttacking(ally)
+	ally.StartAttacking(brute)
+
+	ctx.Raw = "rescue ally"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if brute.ImmediateDefender() != ally {
+		t.Fatalf("Rescued without the skill")
+	}
+
+	ctx.Raw = "assist ally"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if !player.IsAttacking(brute) || brute.ImmediateDefender() != ally {
+		t.Fatalf("Assist didn't join the fight")
+	}
+
+	player.Skills.Get(Rescue{}.Label()).Base = 100
+	ctx.Raw = "rescue ally"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if brute.ImmediateDefender() != player || player.ImmediateDefender() != brute {
+		t.Fatalf("Rescue didn't draw the brute's attacks")
+	}
+	if !brute.IsAttacking(ally) {
+		t.Fatalf("Rescue stopped the fight with the ally")
+	}
+}
+
+func TestCrowdControl(t *testing.T) {
+	w, player := gaigenInHallway(t)
+	hallway := player.Room
+	target := world.NewPlayer("Target UUID", "Target")
+	hallway.Enter(target)
+	target.Room = hallway
+
+	player.Control(world.Root, 3)
+	ctx := Context{World: w, Player: player, Raw: "flee"}
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	ctx.Raw = hallway.Exits.FirstExit()
+	for name, exit := range hallway.Exits {
+		if exit.RoomUUID == ctx.Raw {
+			ctx.Raw = name
+		}
+	}
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if player.Room != hallway {
+		t.Fatalf("Rooted player left the room")
+	}
+
+	player.Skills.Get("bash").Base = 1
+	player.Control(world.Silence, 3)
+	ctx.Raw = "bash target"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if player.IsFighting() || player.OnCoolDown("bash") {
+		t.Fatalf("Silenced player used a skill")
+	}
+
+	player.Control(world.Stun, 3)
+	if _, ok := determineCommand("look", ctx).(StunLocked); !ok {
+		t.Fatalf("Stunned player wasn't stun locked")
+	}
+}
+
+func TestDispelCleanse(t *testing.T) {
+	w := build("data/areas")
+	player := w.Players["gaigen"]
+	opponent := world.NewPlayer("Opponent UUID", "Opponent")
+	opponent.Room = player.Room
+	player.Room.Enter(opponent)
+	opponent.Apply(buffs.NewHaste(opponent))
+	opponent.Apply(buffs.NewBarrier(opponent))
+	player.Apply(buffs.NewBleed(player, opponent))
+	player.Apply(buffs.NewWeaken(player, opponent))
+	player.Skills.Get("dispel").Base = 1
+	player.Skills.Get("cleanse").Base = 2
+
+	ctx := Context{World: w, Player: player, Raw: "dispel opponent"}
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if opponent.HasBuff(buffs.HasteName) || !opponent.HasBuff(buffs.BarrierName) || !player.IsAttacking(opponent) {
+		t.Fatalf("Dispel didn't remove the oldest magic")
+	}
+
+	ctx.Raw = "cleanse"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if len(player.Buffs) != 0 {
+		t.Fatalf("Cleanse left %d effects", len(player.Buffs))
+	}
+	player.CoolDowns = nil
+	opponent.Apply(buffs.NewBleed(opponent, player))
+	ctx.Raw = "cleanse opponent"
+	determineCommand(ctx.Raw, ctx).Execute(ctx)
+	if opponent.HasBuff(buffs.BleedName) || !opponent.HasBuff(buffs.BarrierName) {
+		t.Fatalf("Cleanse didn't remove only harmful effects")
+	}
+}
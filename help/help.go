@@ -14,7 +14,7 @@ type Content struct {
 
 type YAMLHelp struct {
 	UUID     string   `yaml:"UUID"`
-	Keywords []string `yaml:"Keywords""`
+	Keywords []string `yaml:"Keywords"`
 	Content  string   `yaml:"Content"`
 }
 
@@ -64,13 +64,17 @@ func Build(root string) map[string]YAMLHelp {
 		if f.IsDir() {
 			continue
 		}
-		temp := buildFromPath(root + "/" + f.Name())
-		for _, keyword := range temp.Keywords {
-			for i := range keyword {
-				alias := keyword[:i+1]
-				index[alias] = temp
-			}
-		}
+		Add(index, buildFromPath(root+"/"+f.Name()))
 	}
 	return index
 }
+
+// Add indexes help under every prefix of its keywords.
+func Add(index map[string]YAMLHelp, h YAMLHelp) {
+	for _, keyword := range h.Keywords {
+		for i := range keyword {
+			alias := keyword[:i+1]
+			index[alias] = h
+		}
+	}
+}
@@ -1,31 +1,105 @@
 package main
 
 import (
-	"bufio"
-	"fmt"
 	"github.com/michaelvmata/path/session"
-	"os"
+	"log"
+	"net"
 	"strings"
+	"time"
 )
 
-func handleInput(incoming chan string) {
-	reader := bufio.NewReader(os.Stdin)
+// WriteTimeout is how long a client gets to accept output before it is
+// disconnected.
+const WriteTimeout = 10 * time.Second
+
+// Server accepts connections and hands each one to the main loop as a
+// session.  The main loop owns all game state, so the server only ever
+// communicates with it through channels.
+type Server struct {
+	Connected    chan *session.Session
+	Ready        chan *session.Session
+	Disconnected chan *session.Session
+}
+
+// Connected is unbuffered so the main loop has registered a session before
+// any of its input is ready.
+func NewServer() *Server {
+	return &Server{
+		Connected:    make(chan *session.Session),
+		Ready:        make(chan *session.Session, 256),
+		Disconnected: make(chan *session.Session, 16),
+	}
+}
+
+func (s *Server) Listen(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	log.Printf("Listening on %s", listener.Addr())
 	for {
-		text, err := reader.ReadString('\n')
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Printf("Error accepting connection: %v", err)
+			continue
+		}
+		go s.handleConnection(conn)
+	}
+}
+
+func (s *Server) handleConnection(conn net.Conn) {
+	log.Printf("Connection from %s", conn.RemoteAddr())
+	sess := session.New()
+	telnet := session.NewTelnet(sess, conn)
+	go handleOutput(sess, conn)
+	s.Connected <- sess
+	telnet.Start()
+	handleInput(s, sess, telnet)
+	s.Disconnected <- sess
+}
+
+func handleInput(server *Server, s *session.Session, telnet *session.Telnet) {
+	for {
+		text, err := telnet.ReadLine()
+		if err == session.LineTooLong {
+			log.Printf("Dropping connection: %v", err)
+		}
 		if err != nil {
 			break
 		}
-		incoming <- strings.TrimSpace(text)
+		s.Incoming <- strings.TrimSpace(text)
+		server.Ready <- s
 	}
 }
 
-func handleOutput(session *session.Session, done chan bool) {
+func handleOutput(s *session.Session, conn net.Conn) {
+	defer conn.Close()
+	go func() {
+		// Closing the connection unblocks a write stuck on a client that
+		// stopped reading.
+		<-s.Done
+		conn.Close()
+	}()
 	for {
 		select {
-		case text := <-session.Outgoing:
-			fmt.Print(Colorize(text))
-		case <-done:
-			break
+		case text := <-s.Outgoing:
+			switch {
+			case session.IsCommand(text):
+				// Negotiation goes out untouched
+			case s.SupportsColor():
+				text = Colorize(text)
+			default:
+				text = Decolorize(text)
+			}
+			conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+			if _, err := conn.Write([]byte(text)); err != nil {
+				// Closing the connection ends the input loop, which
+				// reports the disconnect.
+				s.Close()
+				return
+			}
+		case <-s.Done:
+			return
 		}
 	}
 }
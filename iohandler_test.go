@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestServerConnection(t *testing.T) {
+	server := NewServer()
+	client, conn := net.Pipe()
+	go server.handleConnection(conn)
+	go io.Copy(io.Discard, client)
+
+	s := <-server.Connected
+	if _, err := client.Write([]byte("look\r\n")); err != nil {
+		t.Fatalf("Unable to write to server %v", err)
+	}
+	if ready := <-server.Ready; ready != s {
+		t.Fatalf("Unexpected session ready")
+	}
+	if text := s.Receive(); text != "look" {
+		t.Fatalf("Unexpected input %s", text)
+	}
+
+	client.Close()
+	if disconnected := <-server.Disconnected; disconnected != s {
+		t.Fatalf("Unexpected session disconnected")
+	}
+	s.Close()
+}
+
+func TestServerDisconnectsSlowClient(t *testing.T) {
+	server := NewServer()
+	client, conn := net.Pipe()
+	defer client.Close()
+	go server.handleConnection(conn)
+
+	// The client never reads, so output backs up until the session closes.
+	s := <-server.Connected
+	for !s.IsClosed() {
+		s.Write("spam")
+	}
+	select {
+	case disconnected := <-server.Disconnected:
+		if disconnected != s {
+			t.Fatalf("Unexpected session disconnected")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Slow client not disconnected")
+	}
+}
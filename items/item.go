@@ -1,20 +1,30 @@
 package item
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/michaelvmata/path/modifiers"
+	"log"
 	"strings"
 )
 
 const (
-	PortalType = "Portal"
-	WeaponType = "Weapon"
-	ArmorType  = "Armor"
+	PortalType     = "Portal"
+	WeaponType     = "Weapon"
+	ArmorType      = "Armor"
+	ShieldType     = "Shield"
+	AmmunitionType = "Ammunition"
 )
 
+// MaximumCondition is the condition of an undamaged item, as a percent.
+const MaximumCondition = 100
+
 type item struct {
 	uuid        string
+	instanceID  string
+	condition   int
 	name        string
 	keywords    []string
 	description string
@@ -27,6 +37,38 @@ func (i *item) UUID() string {
 	return i.uuid
 }
 
+// InstanceID identifies a single spawned copy of an item.  Prototypes have
+// an empty instance ID.
+func (i *item) InstanceID() string {
+	return i.instanceID
+}
+
+// clone copies the item for a new instance so that instances never share
+// keywords or modifiers.
+func (i *item) clone(instanceID string) item {
+	c := *i
+	c.instanceID = instanceID
+	c.keywords = append([]string{}, i.keywords...)
+	c.modifiers = append([]modifiers.Modifier{}, i.modifiers...)
+	return c
+}
+
+// Condition is how worn this instance is, from 0 for broken up to
+// MaximumCondition.
+func (i *item) Condition() int {
+	return i.condition
+}
+
+func (i *item) SetCondition(condition int) {
+	if condition < 0 {
+		condition = 0
+	}
+	if condition > MaximumCondition {
+		condition = MaximumCondition
+	}
+	i.condition = condition
+}
+
 func (i *item) Name() string {
 	return i.name
 }
@@ -53,11 +95,19 @@ func (i *item) Modifiers() []modifiers.Modifier {
 }
 
 func (i *item) Description() string {
+	return i.describe()
+}
+
+// describe lists the item's details, with any type specific details
+// before its modifiers.
+func (i *item) describe(details ...string) string {
 	parts := make([]string, 0)
 	parts = append(parts, fmt.Sprintf("<white>Name: %s", i.Name()))
 	parts = append(parts, fmt.Sprintf("Keywords: %s", strings.Join(i.keywords, ", ")))
+	parts = append(parts, details...)
+	parts = append(parts, fmt.Sprintf("Condition: %d%%", i.condition))
 	for _, modifier := range i.Modifiers() {
-		parts = append(parts, fmt.Sprintf("%s: %d", modifier.Type, modifier.Value))
+		parts = append(parts, modifier.String())
 	}
 	parts = append(parts, "<reset>")
 	parts = append(parts, i.description)
@@ -78,6 +128,10 @@ func (i *item) Type() string {
 
 type Item interface {
 	UUID() string
+	InstanceID() string
+	Instance(string) Item
+	Condition() int
+	SetCondition(int)
 	Name() string
 	HasKeyword(string) bool
 	Modifiers() []modifiers.Modifier
@@ -113,11 +167,25 @@ func NewItem(UUID string, name string, keywords []string, description string, Ty
 			keywords:    keywords,
 			description: description,
 			modifiers:   make([]modifiers.Modifier, 0),
+			condition:   MaximumCondition,
 			itemType:    Type,
 		},
 	}
 }
 
+func (o *Other) Instance(instanceID string) Item {
+	return &Other{item: o.item.clone(instanceID)}
+}
+
+// NewInstanceID returns a random identifier for a spawned item.
+func NewInstanceID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("Unable to generate instance ID: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
+
 type Weapon struct {
 	item
 	DamageType    string
@@ -128,6 +196,13 @@ type Weapon struct {
 	CriticalRate  float64
 }
 
+func (w *Weapon) Instance(instanceID string) Item {
+	c := *w
+	c.item = w.item.clone(instanceID)
+	c.Attributes = append([]string{}, w.Attributes...)
+	return &c
+}
+
 func (w *Weapon) IsBlade() bool {
 	return w.HasAttribute(Blade)
 }
@@ -161,6 +236,7 @@ func NewWeapon(UUID string, name string, keywords []string, description string,
 			keywords:    keywords,
 			description: description,
 			modifiers:   make([]modifiers.Modifier, 0),
+			condition:   MaximumCondition,
 			itemType:    WeaponType,
 		},
 		DamageType: damageType,
@@ -168,9 +244,54 @@ func NewWeapon(UUID string, name string, keywords []string, description string,
 	}
 }
 
+// ArmorScale is the armor rating that halves hit damage.  Each point of
+// armor is worth less than the one before it.
+const ArmorScale = 100
+
 type Armor struct {
 	item
-	Slot string
+	Slot   string
+	Rating int
+}
+
+func (a *Armor) Description() string {
+	return a.describe(fmt.Sprintf("Armor: %d", a.Rating))
+}
+
+func (a *Armor) Instance(instanceID string) Item {
+	c := *a
+	c.item = a.item.clone(instanceID)
+	return &c
+}
+
+// Shield is held in the off hand and can block attacks outright.
+type Shield struct {
+	item
+	BlockRate float64
+}
+
+func (s *Shield) Instance(instanceID string) Item {
+	c := *s
+	c.item = s.item.clone(instanceID)
+	return &c
+}
+
+func (s *Shield) Description() string {
+	return s.describe(fmt.Sprintf("Block: %d%%", int(s.BlockRate*100)))
+}
+
+func NewShield(UUID string, name string, keywords []string, description string) *Shield {
+	return &Shield{
+		item: item{
+			uuid:        UUID,
+			name:        name,
+			keywords:    keywords,
+			description: description,
+			modifiers:   make([]modifiers.Modifier, 0),
+			condition:   MaximumCondition,
+			itemType:    ShieldType,
+		},
+	}
 }
 
 func NewArmor(UUID string, name string, slot string, keywords []string, description string) *Armor {
@@ -181,6 +302,7 @@ func NewArmor(UUID string, name string, slot string, keywords []string, descript
 			keywords:    keywords,
 			description: description,
 			modifiers:   make([]modifiers.Modifier, 0),
+			condition:   MaximumCondition,
 			itemType:    ArmorType,
 		},
 		Slot: slot,
@@ -242,6 +364,19 @@ const (
 	MainHand = "MainHand"
 )
 
+// Slots lists every gear slot in the order gear is shown.
+var Slots = []string{Head, Neck, Body, Arms, Hands, Waist, Legs, Feet, Wrist, Fingers, OffHand, MainHand}
+
+var (
+	NotWearable      = errors.New("not wearable")
+	WrongSlot        = errors.New("can't be worn there")
+	TwoHandedOffHand = errors.New("two handed weapons can't be held in the off hand")
+	OffHandBlocked   = errors.New("off hand is blocked by a two handed weapon")
+	OffHandOccupied  = errors.New("off hand is needed for a two handed weapon")
+)
+
+// Gear holds worn items.  The off hand holds a weapon, a shield or off
+// hand armor.
 type Gear struct {
 	Head     *Armor
 	Neck     *Armor
@@ -253,7 +388,7 @@ type Gear struct {
 	Feet     *Armor
 	Wrist    *Armor
 	Fingers  *Armor
-	OffHand  *Armor
+	OffHand  Item
 	MainHand *Weapon
 }
 
@@ -261,125 +396,189 @@ func NewGear() *Gear {
 	return &Gear{}
 }
 
-func (g *Gear) Remove(keyword string) Item {
-	if g.Head != nil && g.Head.HasKeyword(keyword) {
-		item := g.Head
-		g.Head = nil
-		return item
-	}
-	if g.Neck != nil && g.Neck.HasKeyword(keyword) {
-		item := g.Neck
-		g.Neck = nil
-		return item
-	}
-	if g.Body != nil && g.Body.HasKeyword(keyword) {
-		item := g.Body
-		g.Body = nil
-		return item
-	}
-	if g.Arms != nil && g.Arms.HasKeyword(keyword) {
-		item := g.Arms
-		g.Arms = nil
-		return item
-	}
-	if g.Hands != nil && g.Hands.HasKeyword(keyword) {
-		item := g.Hands
-		g.Hands = nil
-		return item
-	}
-	if g.Waist != nil && g.Waist.HasKeyword(keyword) {
-		item := g.Waist
-		g.Waist = nil
-		return item
-	}
-	if g.Legs != nil && g.Legs.HasKeyword(keyword) {
-		item := g.Legs
-		g.Legs = nil
-		return item
+// At returns the item worn in a slot, or nil.
+func (g *Gear) At(slot string) Item {
+	var worn Item
+	switch slot {
+	case Head:
+		worn = g.Head
+	case Neck:
+		worn = g.Neck
+	case Body:
+		worn = g.Body
+	case Arms:
+		worn = g.Arms
+	case Hands:
+		worn = g.Hands
+	case Waist:
+		worn = g.Waist
+	case Legs:
+		worn = g.Legs
+	case Feet:
+		worn = g.Feet
+	case Wrist:
+		worn = g.Wrist
+	case Fingers:
+		worn = g.Fingers
+	case OffHand:
+		worn = g.OffHand
+	case MainHand:
+		worn = g.MainHand
 	}
-	if g.Feet != nil && g.Feet.HasKeyword(keyword) {
-		item := g.Feet
-		g.Feet = nil
-		return item
+	if IsNil(worn) {
+		return nil
 	}
-	if g.Wrist != nil && g.Wrist.HasKeyword(keyword) {
-		item := g.Wrist
-		g.Wrist = nil
-		return item
+	return worn
+}
+
+// Items returns the worn items in slot order.
+func (g *Gear) Items() []Item {
+	worn := make([]Item, 0)
+	for _, slot := range Slots {
+		if i := g.At(slot); i != nil {
+			worn = append(worn, i)
+		}
 	}
-	if g.Fingers != nil && g.Fingers.HasKeyword(keyword) {
-		item := g.Fingers
-		g.Fingers = nil
-		return item
+	return worn
+}
+
+// OffHandWeapon returns the weapon held in the off hand, or nil.
+func (g *Gear) OffHandWeapon() *Weapon {
+	weapon, _ := g.OffHand.(*Weapon)
+	return weapon
+}
+
+// Shield returns the shield held in the off hand, or nil.
+func (g *Gear) Shield() *Shield {
+	shield, _ := g.OffHand.(*Shield)
+	return shield
+}
+
+// Armor sums the armor rating of all worn armor.
+func (g *Gear) Armor() int {
+	rating := 0
+	for _, i := range g.Items() {
+		if armor, ok := i.(*Armor); ok {
+			rating += armor.Rating
+		}
 	}
-	if g.OffHand != nil && g.OffHand.HasKeyword(keyword) {
-		item := g.OffHand
-		g.OffHand = nil
-		return item
+	return rating
+}
+
+// ArmorReduction is the share of hit damage the worn armor absorbs.
+func (g *Gear) ArmorReduction() float64 {
+	rating := g.Armor()
+	if rating <= 0 {
+		return 0
 	}
-	if g.MainHand != nil && g.MainHand.HasKeyword(keyword) {
-		item := g.MainHand
-		g.MainHand = nil
-		return item
+	return float64(rating) / float64(rating+ArmorScale)
+}
+
+func (g *Gear) Remove(keyword string) Item {
+	for _, slot := range Slots {
+		if i := g.At(slot); i != nil && i.HasKeyword(keyword) {
+			g.set(slot, nil)
+			return i
+		}
 	}
 	return nil
 }
 
-func (g *Gear) Equip(i Item) (Item, error) {
-	var previous Item
-	if weapon, ok := i.(*Weapon); ok {
-		previous = g.MainHand
-		g.MainHand = weapon
-		return previous, nil
-	}
-	armor, ok := i.(*Armor)
-	if !ok {
-		return nil, errors.New("not wearable")
-	}
-	switch armor.Slot {
+func (g *Gear) set(slot string, i Item) {
+	armor, _ := i.(*Armor)
+	switch slot {
 	case Head:
-		previous = g.Head
 		g.Head = armor
 	case Neck:
-		previous = g.Neck
 		g.Neck = armor
 	case Body:
-		previous = g.Body
 		g.Body = armor
 	case Arms:
-		previous = g.Arms
 		g.Arms = armor
 	case Hands:
-		previous = g.Hands
 		g.Hands = armor
 	case Waist:
-		previous = g.Waist
 		g.Waist = armor
 	case Legs:
-		previous = g.Legs
 		g.Legs = armor
 	case Feet:
-		previous = g.Feet
 		g.Feet = armor
 	case Wrist:
-		previous = g.Wrist
 		g.Wrist = armor
 	case Fingers:
-		previous = g.Fingers
 		g.Fingers = armor
 	case OffHand:
-		previous = g.OffHand
-		g.OffHand = armor
+		g.OffHand = i
+	case MainHand:
+		g.MainHand, _ = i.(*Weapon)
 	}
+}
+
+// SlotFor is where an item is worn by default.  Weapons are wielded in the
+// main hand and shields held in the off hand.
+func SlotFor(i Item) string {
+	switch i := i.(type) {
+	case *Weapon:
+		return MainHand
+	case *Shield:
+		return OffHand
+	case *Armor:
+		return i.Slot
+	}
+	return Empty
+}
+
+// Equip wears an item in its default slot and returns the item it
+// replaced.
+func (g *Gear) Equip(i Item) (Item, error) {
+	return g.EquipIn(i, SlotFor(i))
+}
+
+// EquipIn wears an item in the given slot and returns the item it
+// replaced.  Only weapons can be moved to the other hand.
+func (g *Gear) EquipIn(i Item, slot string) (Item, error) {
+	switch i := i.(type) {
+	case *Weapon:
+		if slot != MainHand && slot != OffHand {
+			return nil, WrongSlot
+		}
+		if slot == OffHand && i.IsTwoHanded() {
+			return nil, TwoHandedOffHand
+		}
+		if slot == MainHand && i.IsTwoHanded() && g.At(OffHand) != nil {
+			return nil, OffHandOccupied
+		}
+	case *Shield:
+		if slot != OffHand {
+			return nil, WrongSlot
+		}
+	case *Armor:
+		if slot != i.Slot {
+			return nil, WrongSlot
+		}
+	default:
+		return nil, NotWearable
+	}
+	if slot == OffHand && g.MainHand != nil && g.MainHand.IsTwoHanded() {
+		return nil, OffHandBlocked
+	}
+	previous := g.At(slot)
+	g.set(slot, i)
 	return previous, nil
 }
 
 func IsNil(i Item) bool {
+	if i == nil {
+		return true
+	}
 	if a, ok := i.(*Armor); ok && a == nil {
 		return true
 	}
 	if w, ok := i.(*Weapon); ok && w == nil {
 		return true
 	}
+	if s, ok := i.(*Shield); ok && s == nil {
+		return true
+	}
 	return false
 }
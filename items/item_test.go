@@ -67,3 +67,86 @@ func TestGear(t *testing.T) {
 	}
 
 }
+
+func TestItemInstance(t *testing.T) {
+	prototype := NewWeapon("UUID", "test sword", []string{"sword"}, "", Slash, []string{Blade})
+	prototype.AddModifier(modifiers.Power, 1)
+	i := prototype.Instance("instance")
+	sword, ok := i.(*Weapon)
+	if !ok || sword == prototype {
+		t.Fatalf("Instance is not a new weapon")
+	}
+	if sword.UUID() != "UUID" || sword.InstanceID() != "instance" || prototype.InstanceID() != "" {
+		t.Fatalf("Instance has wrong IDs %s %s", sword.UUID(), sword.InstanceID())
+	}
+	sword.AddModifier(modifiers.Power, 1)
+	if len(prototype.Modifiers()) != 1 {
+		t.Fatalf("Instance shares modifiers with prototype")
+	}
+	sword.SetCondition(-5)
+	other := prototype.Instance("other")
+	if sword.Condition() != 0 || other.Condition() != MaximumCondition {
+		t.Fatalf("Instance condition(%d, %d) not its own", sword.Condition(), other.Condition())
+	}
+	if NewInstanceID() == NewInstanceID() {
+		t.Fatalf("Instance IDs are not unique")
+	}
+}
+
+func TestGearArmor(t *testing.T) {
+	gear := NewGear()
+	if gear.Armor() != 0 || gear.ArmorReduction() != 0 {
+		t.Fatalf("Empty gear has armor")
+	}
+	plate := NewArmor("Plate UUID", "test plate", Body, []string{"plate"}, "")
+	plate.Rating = ArmorScale / 2
+	helmet := NewArmor("Helmet UUID", "test helmet", Head, []string{"helmet"}, "")
+	helmet.Rating = ArmorScale / 2
+	gear.Equip(plate)
+	gear.Equip(helmet)
+	if gear.Armor() != ArmorScale {
+		t.Fatalf("Armor(%d) expected(%d)", gear.Armor(), ArmorScale)
+	}
+	if gear.ArmorReduction() != 0.5 {
+		t.Fatalf("Armor reduction(%f) expected(0.5)", gear.ArmorReduction())
+	}
+	if !strings.Contains(plate.Description(), "Armor: 50") {
+		t.Fatalf("Armor description missing rating: %s", plate.Description())
+	}
+}
+
+func TestGearHands(t *testing.T) {
+	gear := NewGear()
+	sword := NewWeapon("Sword UUID", "test sword", []string{"sword"}, "", Slash, []string{Blade})
+	dagger := NewWeapon("Dagger UUID", "test dagger", []string{"dagger"}, "", Pierce, []string{Blade})
+	spear := NewWeapon("Spear UUID", "test spear", []string{"spear"}, "", Pierce, []string{TwoHanded})
+	shield := NewShield("Shield UUID", "test shield", []string{"shield"}, "")
+
+	if _, err := gear.EquipIn(sword, MainHand); err != nil {
+		t.Fatalf("Unable to wield main hand %v", err)
+	}
+	if _, err := gear.EquipIn(dagger, OffHand); err != nil || gear.OffHandWeapon() != dagger {
+		t.Fatalf("Unable to wield off hand %v", err)
+	}
+	if _, err := gear.EquipIn(spear, OffHand); err != TwoHandedOffHand {
+		t.Fatalf("Two handed weapon held in off hand %v", err)
+	}
+	if _, err := gear.EquipIn(spear, MainHand); err != OffHandOccupied {
+		t.Fatalf("Two handed weapon wielded with full off hand %v", err)
+	}
+	if previous, err := gear.Equip(shield); err != nil || previous != dagger || gear.Shield() != shield {
+		t.Fatalf("Unable to hold shield %v", err)
+	}
+	if _, err := gear.EquipIn(shield, Head); err != WrongSlot {
+		t.Fatalf("Shield worn on head %v", err)
+	}
+	if i := gear.Remove("shield"); i != shield || gear.At(OffHand) != nil {
+		t.Fatalf("Unable to remove shield")
+	}
+	if _, err := gear.EquipIn(spear, MainHand); err != nil {
+		t.Fatalf("Unable to wield two handed weapon %v", err)
+	}
+	if _, err := gear.Equip(shield); err != OffHandBlocked {
+		t.Fatalf("Shield held with two handed weapon %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"github.com/michaelvmata/path/account"
+	"github.com/michaelvmata/path/session"
+	"github.com/michaelvmata/path/world"
+	"log"
+	"strings"
+)
+
+const (
+	StartingRoomUUID = "ab675bc143e84233a543f7e6e7338f11"
+	MaxLoginAttempts = 3
+)
+
+const (
+	askName = iota
+	askPassword
+	confirmNewName
+	askNewPassword
+	confirmNewPassword
+	askClaimToken
+	verifying
+)
+
+// Verified is password hashing finished off the main loop.  Resume picks
+// the login back up on the main loop and returns the character once the
+// player is logged in.
+type Verified struct {
+	Login  *Login
+	Resume func() *world.Character
+}
+
+// Login walks a connection through naming, authenticating and, for new
+// players, creating a character.  Players saved before accounts existed
+// have no password.  They are claimed with a one time token the operator
+// hands out from the server log, then a new password.
+type Login struct {
+	World    *world.World
+	Accounts *account.Accounts
+	Session  *session.Session
+
+	// Verified receives the slow password work so it never blocks the tick
+	// loop.
+	Verified chan<- Verified
+
+	// Save persists a newly created account and character.
+	Save func(*world.Character) error
+
+	// Claims holds the claim token of each player without an account.
+	Claims map[string]string
+
+	// Disconnect is set once the connection has failed too many attempts.
+	Disconnect bool
+
+	state    int
+	name     string
+	password string
+	claiming bool
+	attempts int
+}
+
+func NewLogin(w *world.World, accounts *account.Accounts, s *session.Session, verified chan<- Verified) *Login {
+	return &Login{
+		World:    w,
+		Accounts: accounts,
+		Session:  s,
+		Verified: verified,
+		Save:     func(*world.Character) error { return nil },
+		Claims:   make(map[string]string),
+	}
+}
+
+func (l *Login) show(message string) {
+	l.Session.Write(message)
+}
+
+func (l *Login) AskName() {
+	l.state = askName
+	l.show("By what name are you known? ")
+}
+
+// Handle advances the login with a line of input.  Input is ignored while
+// a password is being checked.
+func (l *Login) Handle(text string) {
+	switch l.state {
+	case askName:
+		l.handleName(text)
+	case askPassword:
+		l.handlePassword(text)
+	case confirmNewName:
+		l.handleConfirmNewName(text)
+	case askNewPassword:
+		l.handleNewPassword(text)
+	case confirmNewPassword:
+		l.handleConfirmNewPassword(text)
+	case askClaimToken:
+		l.handleClaimToken(text)
+	}
+}
+
+// verify runs the slow work on its own goroutine and hands the result back
+// through Verified.
+func (l *Login) verify(work func() func() *world.Character) {
+	l.state = verifying
+	go func() {
+		resume := work()
+		l.Verified <- Verified{Login: l, Resume: resume}
+	}()
+}
+
+func (l *Login) handleName(text string) {
+	name := strings.ToLower(strings.TrimSpace(text))
+	if err := validateName(name); err != nil {
+		l.show("That name won't do.  Names are 3 to 12 letters.\n")
+		l.AskName()
+		return
+	}
+	l.name = name
+	l.claiming = false
+	if l.Accounts.Exists(name) {
+		l.state = askPassword
+		l.show("Password: ")
+		l.Session.HideInput()
+		return
+	}
+	if _, found := l.World.Players[name]; found {
+		if _, ok := l.Claims[name]; !ok {
+			l.show("That name is already taken.\n")
+			l.AskName()
+			return
+		}
+		l.state = askClaimToken
+		l.show(name + " has no password yet.  Claim token: ")
+		l.Session.HideInput()
+		return
+	}
+	l.state = confirmNewName
+	l.show("Create a new character named " + name + "? (yes/no) ")
+}
+
+func (l *Login) handlePassword(text string) {
+	l.Session.ShowInput()
+	l.show("\n")
+	hash, err := l.Accounts.PasswordHash(l.name)
+	if err != nil {
+		l.failPassword(err)
+		return
+	}
+	l.verify(func() func() *world.Character {
+		checked := account.CheckPassword(hash, text)
+		return func() *world.Character {
+			return l.resumePassword(checked)
+		}
+	})
+}
+
+func (l *Login) resumePassword(checked error) *world.Character {
+	if err := l.Accounts.Record(l.name, checked); err != nil {
+		l.failPassword(err)
+		return nil
+	}
+	player, err := determinePlayer(l.name, l.World)
+	if err != nil {
+		l.show("You can't play " + l.name + " right now.\n")
+		l.AskName()
+		return nil
+	}
+	return player
+}
+
+func (l *Login) failPassword(err error) {
+	if err == account.AccountLocked {
+		l.show("Too many failed attempts.  Try again later.\n")
+		l.Disconnect = true
+		return
+	}
+	l.attempts++
+	if l.attempts >= MaxLoginAttempts {
+		l.show("Too many failed attempts.\n")
+		l.Disconnect = true
+		return
+	}
+	l.show("Wrong password.\n")
+	l.AskName()
+}
+
+func (l *Login) handleClaimToken(text string) {
+	l.Session.ShowInput()
+	l.show("\n")
+	token := l.Claims[l.name]
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(text)) != 1 {
+		l.failPassword(account.InvalidCredentials)
+		return
+	}
+	l.claiming = true
+	l.state = askNewPassword
+	l.show("Choose a password: ")
+	l.Session.HideInput()
+}
+
+func (l *Login) handleConfirmNewName(text string) {
+	answer := strings.ToLower(strings.TrimSpace(text))
+	if answer != "y" && answer != "yes" {
+		l.AskName()
+		return
+	}
+	l.state = askNewPassword
+	l.show("Choose a password: ")
+	l.Session.HideInput()
+}
+
+func (l *Login) handleNewPassword(text string) {
+	l.show("\n")
+	if len(text) < account.MinPasswordLen {
+		l.show("Passwords need at least 6 characters.\n")
+		l.show("Choose a password: ")
+		return
+	}
+	l.password = text
+	l.state = confirmNewPassword
+	l.show("Confirm password: ")
+}
+
+func (l *Login) handleConfirmNewPassword(text string) {
+	l.Session.ShowInput()
+	l.show("\n")
+	password := l.password
+	l.password = ""
+	if text != password {
+		l.show("Passwords don't match.\n")
+		l.state = askNewPassword
+		l.show("Choose a password: ")
+		l.Session.HideInput()
+		return
+	}
+	l.verify(func() func() *world.Character {
+		hash, err := account.HashPassword(password)
+		return func() *world.Character {
+			return l.resumeCreate(hash, err)
+		}
+	})
+}
+
+func (l *Login) resumeCreate(hash string, err error) *world.Character {
+	// The name may have been taken while the password was hashed.
+	_, taken := l.World.Players[l.name]
+	if err != nil || taken != l.claiming || l.Accounts.Exists(l.name) {
+		l.show("Unable to create " + l.name + ".\n")
+		l.AskName()
+		return nil
+	}
+	var player *world.Character
+	if l.claiming {
+		player, err = determinePlayer(l.name, l.World)
+	} else {
+		player, err = newCharacter(l.World, l.name)
+	}
+	if err != nil {
+		log.Printf("Unable to create character %s: %v", l.name, err)
+		l.show("Unable to create " + l.name + ".\n")
+		l.AskName()
+		return nil
+	}
+	l.Accounts.Add(l.name, hash)
+	if err := l.Save(player); err != nil {
+		log.Printf("Unable to save account %s: %v", l.name, err)
+		// Undo the creation so the name can be tried again.
+		l.Accounts.Remove(l.name)
+		if !l.claiming {
+			delete(l.World.Players, l.name)
+			player.Room.Exit(player)
+		}
+		l.show("Unable to save " + l.name + ".  Try again later.\n")
+		l.AskName()
+		return nil
+	}
+	if l.claiming {
+		delete(l.Claims, l.name)
+		log.Printf("Claimed character %s", player.Name)
+		return player
+	}
+	log.Printf("Created character %s", player.Name)
+	return player
+}
+
+func validateName(name string) error {
+	if len(name) < 3 || len(name) > 12 {
+		return errors.New("invalid name length")
+	}
+	for _, r := range name {
+		if r < 'a' || r > 'z' {
+			return errors.New("invalid name character")
+		}
+	}
+	return nil
+}
+
+// newClaims gives every player without an account a claim token, logging
+// them for the operator to hand out.
+func newClaims(w *world.World, accounts *account.Accounts) map[string]string {
+	claims := make(map[string]string)
+	for name := range w.Players {
+		if accounts.Exists(name) {
+			continue
+		}
+		claims[name] = newUUID()
+		log.Printf("Claim token for %s: %s", name, claims[name])
+	}
+	return claims
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("Unable to generate UUID: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func newCharacter(w *world.World, name string) (*world.Character, error) {
+	room, ok := w.Rooms[StartingRoomUUID]
+	if !ok {
+		return nil, errors.New("starting room not found")
+	}
+	c := world.NewPlayer(newUUID(), name)
+	c.Restore()
+	if err := room.Enter(c); err != nil {
+		return nil, err
+	}
+	c.Room = room
+	c.Anchor = room
+	w.Players[c.Name] = c
+	return c, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"github.com/michaelvmata/path/account"
+	"github.com/michaelvmata/path/session"
+	"github.com/michaelvmata/path/world"
+	"path/filepath"
+	"testing"
+)
+
+// resume waits for the login's password work like the main loop does.
+func resume(verified chan Verified) *world.Character {
+	return (<-verified).Resume()
+}
+
+func TestLoginExistingPlayer(t *testing.T) {
+	w := build("data/areas")
+	accounts := account.New()
+	accounts.Create("gaigen", "password")
+	verified := make(chan Verified)
+	login := NewLogin(w, accounts, session.New(), verified)
+
+	login.AskName()
+	login.Handle("gaigen")
+	login.Handle("wrong")
+	login.Handle("ignored while verifying")
+	if player := resume(verified); player != nil {
+		t.Fatalf("Logged in with wrong password")
+	}
+	login.Handle("gaigen")
+	login.Handle("password")
+	if player := resume(verified); player != w.Players["gaigen"] {
+		t.Fatalf("Unable to log in with password")
+	}
+}
+
+func TestLoginAttempts(t *testing.T) {
+	w := build("data/areas")
+	accounts := account.New()
+	accounts.Create("gaigen", "password")
+	verified := make(chan Verified)
+	login := NewLogin(w, accounts, session.New(), verified)
+
+	for i := 0; i < MaxLoginAttempts; i++ {
+		login.Handle("gaigen")
+		login.Handle("wrong")
+		resume(verified)
+	}
+	if !login.Disconnect {
+		t.Fatalf("Connection not dropped after %d attempts", MaxLoginAttempts)
+	}
+}
+
+func TestLoginNewPlayer(t *testing.T) {
+	w := build("data/areas")
+	accounts := account.New()
+	verified := make(chan Verified)
+	login := NewLogin(w, accounts, session.New(), verified)
+	saved := false
+	login.Save = func(*world.Character) error {
+		saved = true
+		return nil
+	}
+
+	login.Handle("newbie")
+	login.Handle("yes")
+	login.Handle("secret")
+	login.Handle("mismatch")
+	if accounts.Exists("newbie") {
+		t.Fatalf("Created player with mismatched passwords")
+	}
+	login.Handle("secret")
+	login.Handle("secret")
+	player := resume(verified)
+	if player == nil || player.Room == nil {
+		t.Fatalf("Unable to create new player")
+	}
+	if !accounts.Exists("newbie") || w.Players["newbie"] != player || !saved {
+		t.Fatalf("New player not registered")
+	}
+}
+
+func TestLoginClaimPlayer(t *testing.T) {
+	w := build("data/areas")
+	accounts := account.New()
+	verified := make(chan Verified)
+	login := NewLogin(w, accounts, session.New(), verified)
+	login.Claims = newClaims(w, accounts)
+	token := login.Claims["gaigen"]
+
+	for _, text := range []string{"gaigen", token, "secret", "secret"} {
+		login.Handle(text)
+	}
+	if player := resume(verified); player != w.Players["gaigen"] {
+		t.Fatalf("Unable to claim existing player")
+	}
+	if !accounts.Exists("gaigen") || login.Claims["gaigen"] != "" {
+		t.Fatalf("Claimed player has no account")
+	}
+}
+
+func TestLoginClaimStranger(t *testing.T) {
+	w := build("data/areas")
+	accounts := account.New()
+	verified := make(chan Verified)
+	login := NewLogin(w, accounts, session.New(), verified)
+
+	// Without a claim token the name is simply taken
+	login.Handle("gaigen")
+	if login.state != askName {
+		t.Fatalf("Stranger offered a player without a claim token")
+	}
+
+	login = NewLogin(w, accounts, session.New(), verified)
+	login.Claims = newClaims(w, accounts)
+	for _, text := range []string{"gaigen", "guess", "secret", "secret"} {
+		login.Handle(text)
+	}
+	if accounts.Exists("gaigen") || login.Claims["gaigen"] == "" || login.state != askName {
+		t.Fatalf("Stranger claimed a player with the wrong token")
+	}
+}
+
+func TestLoginSaveFailure(t *testing.T) {
+	w := build("data/areas")
+	accounts := account.New()
+	verified := make(chan Verified)
+	login := NewLogin(w, accounts, session.New(), verified)
+	login.Save = func(*world.Character) error {
+		return saveAccounts(accounts, filepath.Join(t.TempDir(), "missing", "account.yaml"))
+	}
+
+	for _, text := range []string{"newbie", "yes", "secret", "secret"} {
+		login.Handle(text)
+	}
+	if player := resume(verified); player != nil {
+		t.Fatalf("Logged in without saving the account")
+	}
+	if accounts.Exists("newbie") || w.Players["newbie"] != nil {
+		t.Fatalf("Unsaved account kept")
+	}
+}
+
+func TestSaveAccounts(t *testing.T) {
+	accounts := account.New()
+	accounts.Add("gaigen", "hash")
+	path := filepath.Join(t.TempDir(), "account.yaml")
+	if err := saveAccounts(accounts, path); err != nil {
+		t.Fatalf("Unable to save accounts %v", err)
+	}
+	if saved := buildAccounts(path); !saved.Exists("gaigen") {
+		t.Fatalf("Saved account not loaded")
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"", "ab", "thirteenchars", "gai gen", "gaigen1"} {
+		if validateName(name) == nil {
+			t.Fatalf("Invalid name %s accepted", name)
+		}
+	}
+	if validateName("gaigen") != nil {
+		t.Fatalf("Valid name rejected")
+	}
+}
@@ -1,55 +1,113 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"github.com/michaelvmata/path/actions"
 	"github.com/michaelvmata/path/events"
 	"github.com/michaelvmata/path/help"
 	"github.com/michaelvmata/path/session"
 	"github.com/michaelvmata/path/simulate"
 	"github.com/michaelvmata/path/title"
+	"github.com/michaelvmata/path/world"
+	"log"
 	"time"
 )
 
 func main() {
+	address := flag.String("address", ":4000", "address to accept connections on")
+	storeType := flag.String("store", "yaml", "persistence backend, yaml or sqlite")
+	database := flag.String("database", "data/path.db", "database file for the sqlite store")
+	seed := flag.Int64("seed", 0, "seed for combat randomness, 0 seeds from the clock")
+	flag.Parse()
+
+	store, err := openStore(*storeType, *database)
+	if err != nil {
+		log.Fatalf("Unable to open %s store: %v", *storeType, err)
+	}
+	defer store.Close()
+
 	ticker := time.NewTicker(time.Second)
-	s := session.New()
-	done := make(chan bool)
-	go handleInput(s.Incoming)
-	go handleOutput(s, done)
-	w := build("data/areas")
-	ctx := Context{
-		World: w,
-		Help:  help.Build("data/help"),
+	w := buildFromStore(store)
+	if *seed != 0 {
+		w.Seed(*seed)
+	}
+	w.Saver = func(player *world.Character) {
+		if err := store.SaveCharacter(player); err != nil {
+			log.Printf("Unable to save player %s: %v", player.Name, err)
+		}
+	}
+	addTechniques(commands, w.Skills)
+	helps := help.Build("data/help")
+	for _, name := range w.Skills.Names() {
+		definition := w.Skills[name]
+		keywords := append([]string{name}, definition.Keywords...)
+		help.Add(helps, help.YAMLHelp{Keywords: keywords, Content: definition.Help})
 	}
 	w.SpawnMobiles()
 	events.CharacterDeath.Init(w)
 	events.CharacterDeath.Register(actions.RespawnCharacter{})
 	events.CharacterDeath.Register(actions.EssenceOnDeath{})
 	events.CharacterDeath.Register(actions.QuestOnDeath{})
-	title.ListCharacters(s, w.Players)
-MainLoop:
+
+	server := NewServer()
+	go func() {
+		if err := server.Listen(*address); err != nil {
+			log.Fatalf("Unable to listen on %s: %v", *address, err)
+		}
+	}()
+
+	accounts := buildAccounts(AccountsPath)
+	claims := newClaims(w, accounts)
+	contexts := make(map[*session.Session]*Context)
+	logins := make(map[*session.Session]*Login)
+	verified := make(chan Verified)
 	for {
 		select {
-		case text := <-s.Incoming:
-			if text == "quit" {
-				done <- true
-				break MainLoop
+		case s := <-server.Connected:
+			contexts[s] = &Context{
+				World: w,
+				Store: store,
+				Help:  helps,
 			}
-			if ctx.Player == nil {
-				player, err := determinePlayer(text, w)
-				if err == nil {
-					ctx.Player = player
-					ctx.Player.Session = s
-					s.PlayerName = text
-					ctx.Player.ShowPrompt()
+			login := NewLogin(w, accounts, s, verified)
+			login.Claims = claims
+			login.Save = func(player *world.Character) error {
+				if err := saveAccounts(accounts, AccountsPath); err != nil {
+					return err
 				}
-			} else {
-				command := determineCommand(text, ctx)
-				ctx.Raw = text
-				command.Execute(ctx)
-				ctx.Player.ShowPrompt()
+				w.Saver(player)
+				return nil
 			}
-
+			logins[s] = login
+			title.Welcome(s)
+			login.AskName()
+		case s := <-server.Ready:
+			ctx, ok := contexts[s]
+			if !ok {
+				continue
+			}
+			text := s.Receive()
+			if ctx.Player == nil {
+				logins[s].Handle(text)
+				finishLogin(contexts, logins, s, nil)
+				continue
+			}
+			if text == "quit" {
+				disconnect(contexts, s)
+				continue
+			}
+			handleText(ctx, text)
+		case v := <-verified:
+			s := v.Login.Session
+			if logins[s] != v.Login {
+				// Disconnected while the password was checked
+				continue
+			}
+			finishLogin(contexts, logins, s, v.Resume())
+		case s := <-server.Disconnected:
+			delete(logins, s)
+			disconnect(contexts, s)
 		case <-ticker.C:
 			w.Update()
 			if w.IsBattleTick() {
@@ -57,5 +115,60 @@ MainLoop:
 			}
 		}
 	}
-	ticker.Stop()
+}
+
+// finishLogin drops connections that failed to log in and attaches the
+// player once they have.
+func finishLogin(contexts map[*session.Session]*Context, logins map[*session.Session]*Login, s *session.Session, player *world.Character) {
+	if logins[s].Disconnect {
+		delete(logins, s)
+		disconnect(contexts, s)
+	} else if player != nil {
+		delete(logins, s)
+		attachPlayer(contexts[s], player, s)
+	}
+}
+
+func attachPlayer(ctx *Context, player *world.Character, s *session.Session) {
+	ctx.Player = player
+	player.Session = s
+	s.PlayerName = player.Name
+	log.Printf("%s connected", player.Name)
+	player.ShowNewline()
+	player.ShowPrompt()
+}
+
+func openStore(storeType string, database string) (Store, error) {
+	switch storeType {
+	case "yaml":
+		return NewYAMLStore("data/areas", PlayersPath), nil
+	case "sqlite":
+		return NewSQLStore("data/areas", database)
+	}
+	return nil, fmt.Errorf("unknown store %s", storeType)
+}
+
+func handleText(ctx *Context, text string) {
+	command := determineCommand(text, *ctx)
+	ctx.Raw = text
+	command.Execute(*ctx)
+	ctx.Player.ShowPrompt()
+}
+
+func disconnect(contexts map[*session.Session]*Context, s *session.Session) {
+	if ctx, ok := contexts[s]; ok && ctx.Player != nil {
+		detachPlayer(ctx.World, ctx.Player, s)
+	}
+	delete(contexts, s)
+	s.Close()
+}
+
+func detachPlayer(w *world.World, player *world.Character, s *session.Session) {
+	if player.Session == s {
+		player.Session = nil
+	}
+	log.Printf("%s disconnected", player.Name)
+	if w.Saver != nil {
+		w.Saver(player)
+	}
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/michaelvmata/path/items"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+// CharacterVersion is the schema version written with every saved
+// character.  Bump it and register a migration whenever a field of
+// YAMLMobile is added, renamed or changes meaning.
+const CharacterVersion = 4
+
+var NewerCharacterVersion = errors.New("character saved by a newer version")
+
+// characterMigration upgrades a raw character document from the version it
+// is registered under to the next one.
+type characterMigration func(doc map[string]interface{}) error
+
+// characterMigrations is keyed by the version a migration upgrades from.
+// Characters saved before versioning have no Version field and start at 0.
+var characterMigrations = map[int]characterMigration{
+	0: migrateCharacterAnchor,
+	1: migrateCharacterItems,
+	2: migrateCharacterSkills,
+	3: migrateCharacterCondition,
+}
+
+// migrateCharacterAnchor gives characters saved before anchors were
+// persisted their current room as an anchor.
+func migrateCharacterAnchor(doc map[string]interface{}) error {
+	if anchor, _ := doc["Anchor"].(string); anchor == "" {
+		doc["Anchor"] = doc["RoomUUID"]
+	}
+	return nil
+}
+
+// migrateCharacterItems turns the item UUIDs saved in Gear and Inventory
+// into item instances, each with its own instance ID.
+func migrateCharacterItems(doc map[string]interface{}) error {
+	instances := make([]interface{}, 0)
+	if gear, ok := doc["Gear"].(map[string]interface{}); ok {
+		for _, slot := range item.Slots {
+			if UUID, _ := gear[slot].(string); UUID != "" {
+				instances = append(instances, map[string]interface{}{
+					"UUID":       UUID,
+					"InstanceID": item.NewInstanceID(),
+					"Slot":       slot,
+				})
+			}
+		}
+	}
+	if inventory, ok := doc["Inventory"].([]interface{}); ok {
+		for _, value := range inventory {
+			if UUID, _ := value.(string); UUID != "" {
+				instances = append(instances, map[string]interface{}{
+					"UUID":       UUID,
+					"InstanceID": item.NewInstanceID(),
+				})
+			}
+		}
+	}
+	delete(doc, "Gear")
+	delete(doc, "Inventory")
+	doc["Items"] = instances
+	return nil
+}
+
+// migrateCharacterSkills keys skills by the lower case name used by the
+// skills registry.
+func migrateCharacterSkills(doc map[string]interface{}) error {
+	skills, ok := doc["Skills"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	migrated := make(map[string]interface{}, len(skills))
+	for name, level := range skills {
+		migrated[strings.ToLower(name)] = level
+	}
+	doc["Skills"] = migrated
+	return nil
+}
+
+// migrateCharacterCondition gives items saved before conditions were
+// tracked a perfect condition.
+func migrateCharacterCondition(doc map[string]interface{}) error {
+	instances, _ := doc["Items"].([]interface{})
+	for _, value := range instances {
+		if instance, ok := value.(map[string]interface{}); ok {
+			if _, found := instance["Condition"]; !found {
+				instance["Condition"] = item.MaximumCondition
+			}
+		}
+	}
+	return nil
+}
+
+func characterVersion(doc map[string]interface{}) (int, error) {
+	value, found := doc["Version"]
+	if !found {
+		return 0, nil
+	}
+	version, ok := value.(int)
+	if !ok {
+		return 0, fmt.Errorf("invalid character version %v", value)
+	}
+	return version, nil
+}
+
+// migrateCharacter decodes a saved character, upgrading it one version at a
+// time to CharacterVersion.  Documents from a newer version are refused
+// rather than loaded with missing fields.
+func migrateCharacter(data []byte) (YAMLMobile, error) {
+	rp := YAMLMobile{}
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return rp, err
+	}
+	version, err := characterVersion(doc)
+	if err != nil {
+		return rp, err
+	}
+	if version > CharacterVersion {
+		return rp, fmt.Errorf("%w: version %d, expected at most %d",
+			NewerCharacterVersion, version, CharacterVersion)
+	}
+	for ; version < CharacterVersion; version++ {
+		migration, ok := characterMigrations[version]
+		if !ok {
+			return rp, fmt.Errorf("no migration from character version %d", version)
+		}
+		if err := migration(doc); err != nil {
+			return rp, fmt.Errorf("migrating character version %d: %w", version, err)
+		}
+		doc["Version"] = version + 1
+	}
+	migrated, err := yaml.Marshal(doc)
+	if err != nil {
+		return rp, err
+	}
+	if err := yaml.Unmarshal(migrated, &rp); err != nil {
+		return rp, err
+	}
+	return rp, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"github.com/michaelvmata/path/items"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateCharacter(t *testing.T) {
+	data := []byte("UUID: abc\nName: gaigen\nRoomUUID: room\n")
+	rp, err := migrateCharacter(data)
+	if err != nil {
+		t.Fatalf("Unable to migrate character: %v", err)
+	}
+	if rp.Version != CharacterVersion {
+		t.Fatalf("Version %d, expected %d", rp.Version, CharacterVersion)
+	}
+	if rp.Anchor != "room" || rp.Name != "gaigen" {
+		t.Fatalf("Character not migrated %+v", rp)
+	}
+}
+
+func TestMigrateCharacterCurrent(t *testing.T) {
+	current := YAMLMobile{
+		Version:  CharacterVersion,
+		UUID:     "abc",
+		Name:     "gaigen",
+		RoomUUID: "room",
+		Anchor:   "home",
+		Items:    []YAMLItemInstance{{UUID: "sword", InstanceID: "instance", Condition: 40}},
+		Skills:   map[string]int{"Parry": 2},
+		// Saved quests load back empty rather than nil
+		Quests: []YAMLMobileQuests{},
+	}
+	data, err := yaml.Marshal(current)
+	if err != nil {
+		t.Fatalf("Unable to save character: %v", err)
+	}
+	rp, err := migrateCharacter(data)
+	if err != nil {
+		t.Fatalf("Unable to load character: %v", err)
+	}
+	if !reflect.DeepEqual(rp, current) {
+		t.Fatalf("Current character migrated %+v", rp)
+	}
+}
+
+func TestMigrateCharacterFromVersion1(t *testing.T) {
+	data := []byte("Version: 1\nUUID: abc\nName: gaigen\nRoomUUID: room\nAnchor: home\nInventory:\n  - sword\n")
+	rp, err := migrateCharacter(data)
+	if err != nil {
+		t.Fatalf("Unable to migrate character: %v", err)
+	}
+	if rp.Version != CharacterVersion || rp.Anchor != "home" {
+		t.Fatalf("Character not migrated from version 1 %+v", rp)
+	}
+	if len(rp.Items) != 1 || rp.Items[0].Condition != item.MaximumCondition {
+		t.Fatalf("Items not migrated from version 1 %+v", rp.Items)
+	}
+}
+
+func TestMigrateCharacterNewer(t *testing.T) {
+	data := []byte("Version: 99\nUUID: abc\nName: gaigen\n")
+	if _, err := migrateCharacter(data); !errors.Is(err, NewerCharacterVersion) {
+		t.Fatalf("Newer character loaded, error %v", err)
+	}
+}
+
+func TestMigrateCharacterItems(t *testing.T) {
+	data := []byte("Version: 1\nUUID: abc\nName: gaigen\nGear:\n  Head: helmet\n  Neck: \"\"\nInventory:\n  - sword\n")
+	rp, err := migrateCharacter(data)
+	if err != nil {
+		t.Fatalf("Unable to migrate character: %v", err)
+	}
+	if len(rp.Items) != 2 || rp.Gear.Head != "" || len(rp.Inventory) != 0 {
+		t.Fatalf("Items not migrated %+v", rp)
+	}
+	if rp.Items[0].UUID != "helmet" || rp.Items[0].Slot != "Head" || rp.Items[0].InstanceID == "" {
+		t.Fatalf("Gear not migrated %+v", rp.Items[0])
+	}
+	if rp.Items[0].Condition != item.MaximumCondition {
+		t.Fatalf("Item condition not migrated %+v", rp.Items[0])
+	}
+	if rp.Items[1].UUID != "sword" || rp.Items[1].Slot != "" {
+		t.Fatalf("Inventory not migrated %+v", rp.Items[1])
+	}
+}
+
+func TestMigrateCharacterSkills(t *testing.T) {
+	data := []byte("Version: 2\nUUID: abc\nName: gaigen\nSkills:\n  Parry: 2\n  Backstab: 1\n")
+	rp, err := migrateCharacter(data)
+	if err != nil {
+		t.Fatalf("Unable to migrate character: %v", err)
+	}
+	if rp.Skills["parry"] != 2 || rp.Skills["backstab"] != 1 || len(rp.Skills) != 2 {
+		t.Fatalf("Skills not migrated %+v", rp.Skills)
+	}
+}
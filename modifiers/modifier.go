@@ -1,5 +1,10 @@
 package modifiers
 
+import (
+	"fmt"
+	"strings"
+)
+
 const (
 	Power   = "Power"
 	Agility = "Agility"
@@ -7,7 +12,29 @@ const (
 	Will    = "Will"
 )
 
+// Resistances and vulnerabilities are percentages of damage of a type
+// that are avoided or added.
+const (
+	CrushResistance     = "CrushResistance"
+	PierceResistance    = "PierceResistance"
+	SlashResistance     = "SlashResistance"
+	CrushVulnerability  = "CrushVulnerability"
+	PierceVulnerability = "PierceVulnerability"
+	SlashVulnerability  = "SlashVulnerability"
+)
+
 type Modifier struct {
 	Type  string
 	Value int
 }
+
+func (m Modifier) IsPercentage() bool {
+	return strings.HasSuffix(m.Type, "Resistance") || strings.HasSuffix(m.Type, "Vulnerability")
+}
+
+func (m Modifier) String() string {
+	if m.IsPercentage() {
+		return fmt.Sprintf("%s: %d%%", m.Type, m.Value)
+	}
+	return fmt.Sprintf("%s: %d", m.Type, m.Value)
+}
@@ -281,3 +281,14 @@ func Colorize(content string) string {
 	r := strings.NewReplacer(args...)
 	return r.Replace(content) + RESET
 }
+
+// Decolorize strips color tags for clients that can't render them.
+func Decolorize(content string) string {
+	args := make([]string, 0)
+	for _, color := range Colors {
+		args = append(args, fmt.Sprintf("<%s>", color), "")
+	}
+	args = append(args, "<reset>", "")
+	r := strings.NewReplacer(args...)
+	return r.Replace(content)
+}
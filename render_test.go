@@ -20,3 +20,12 @@ func TestRender(t *testing.T) {
 		}
 	}
 }
+
+func TestDecolorize(t *testing.T) {
+	for _, color := range Colors {
+		c := Decolorize(fmt.Sprintf("<%s>%s<reset>", color, color))
+		if c != color {
+			t.Fatalf("Color %s, %s didn't strip tags", color, c)
+		}
+	}
+}
@@ -1,15 +1,33 @@
 package session
 
+import (
+	"strings"
+	"sync"
+)
+
+const DefaultWidth = 60
+
 type Session struct {
 	PlayerName string
 	Incoming   chan string
 	Outgoing   chan string
+	Done       chan bool
+
+	closeOnce sync.Once
+	telnet    *Telnet
+
+	// Negotiated by the telnet layer on the input goroutine.
+	mu           sync.Mutex
+	width        int
+	height       int
+	terminalType string
 }
 
 func New() *Session {
 	s := Session{
-		Incoming: make(chan string),
-		Outgoing: make(chan string),
+		Incoming: make(chan string, 32),
+		Outgoing: make(chan string, 256),
+		Done:     make(chan bool),
 	}
 	return &s
 }
@@ -29,6 +47,81 @@ func (s *Session) Receive() string {
 
 func (s *Session) Send(messages []string) {
 	for _, message := range messages {
-		s.Outgoing <- message
+		s.Write(message)
+	}
+}
+
+// Write queues a message without blocking the caller.  A client that lets
+// Outgoing fill up has stopped reading, so the session is closed instead.
+func (s *Session) Write(message string) {
+	select {
+	case s.Outgoing <- message:
+	default:
+		s.Close()
+	}
+}
+
+// Close signals the session's handlers to stop.  It is safe to call more
+// than once.
+func (s *Session) Close() {
+	s.closeOnce.Do(func() {
+		close(s.Done)
+	})
+}
+
+func (s *Session) IsClosed() bool {
+	select {
+	case <-s.Done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Session) SetWindowSize(width int, height int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.width = width
+	s.height = height
+}
+
+// Width is the client's negotiated window width, or DefaultWidth when the
+// client never reported one.
+func (s *Session) Width() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.width <= 0 {
+		return DefaultWidth
+	}
+	return s.width
+}
+
+func (s *Session) SetTerminalType(terminalType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.terminalType = terminalType
+}
+
+func (s *Session) TerminalType() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.terminalType
+}
+
+// SupportsColor assumes color unless the client explicitly reports a dumb
+// terminal, since most clients never answer TTYPE.
+func (s *Session) SupportsColor() bool {
+	return strings.ToLower(s.TerminalType()) != "dumb"
+}
+
+func (s *Session) HideInput() {
+	if s.telnet != nil {
+		s.telnet.HideInput()
+	}
+}
+
+func (s *Session) ShowInput() {
+	if s.telnet != nil {
+		s.telnet.ShowInput()
 	}
 }
@@ -10,4 +10,30 @@ func TestSession(t *testing.T) {
 	if r := s.Receive(); r != "" {
 		log.Fatalf("Unexpected result %s", r)
 	}
+	s.Incoming <- "look"
+	if r := s.Receive(); r != "look" {
+		t.Fatalf("Unexpected result %s", r)
+	}
+}
+
+func TestSessionWriteFull(t *testing.T) {
+	s := New()
+	for i := 0; i < cap(s.Outgoing)+1; i++ {
+		s.Write("spam")
+	}
+	if !s.IsClosed() {
+		t.Fatalf("Session not closed when output backed up")
+	}
+}
+
+func TestSessionClose(t *testing.T) {
+	s := New()
+	if s.IsClosed() {
+		t.Fatalf("New session is closed")
+	}
+	s.Close()
+	s.Close()
+	if !s.IsClosed() {
+		t.Fatalf("Session not closed")
+	}
 }
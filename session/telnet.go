@@ -0,0 +1,203 @@
+package session
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Telnet commands and options from RFC 854, 857, 1073 and 1091.
+const (
+	IAC  byte = 255
+	DONT byte = 254
+	DO   byte = 253
+	WONT byte = 252
+	WILL byte = 251
+	SB   byte = 250
+	SE   byte = 240
+
+	ECHO  byte = 1
+	TTYPE byte = 24
+	NAWS  byte = 31
+
+	IS   byte = 0
+	SEND byte = 1
+)
+
+// MaxLineLength caps a line of input so a client can't grow server memory
+// without limit.
+const MaxLineLength = 1024
+
+var LineTooLong = errors.New("line too long")
+
+// Telnet sits between a connection and its session.  It strips protocol
+// negotiation out of the input stream, answers it, and records what the
+// client reports about itself on the session.  Commands go out through the
+// session's output queue so they never block the caller.
+type Telnet struct {
+	session *Session
+	reader  *bufio.Reader
+
+	mu     sync.Mutex
+	hidden bool
+}
+
+func NewTelnet(s *Session, conn io.Reader) *Telnet {
+	t := &Telnet{
+		session: s,
+		reader:  bufio.NewReader(conn),
+	}
+	s.telnet = t
+	return t
+}
+
+// Start asks the client for its window size and terminal type.
+func (t *Telnet) Start() {
+	t.send(IAC, DO, NAWS, IAC, DO, TTYPE)
+}
+
+func (t *Telnet) send(data ...byte) {
+	t.session.Write(string(data))
+}
+
+// IsCommand reports whether an outgoing message is telnet negotiation,
+// which must be written without any color handling.
+func IsCommand(message string) bool {
+	return len(message) > 0 && message[0] == IAC
+}
+
+// ReadLine returns the next line of input with any negotiation removed.
+func (t *Telnet) ReadLine() (string, error) {
+	line := make([]byte, 0)
+	for {
+		if len(line) > MaxLineLength {
+			return "", LineTooLong
+		}
+		b, err := t.reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch b {
+		case IAC:
+			next, err := t.reader.ReadByte()
+			if err != nil {
+				return "", err
+			}
+			if next == IAC {
+				line = append(line, IAC)
+				continue
+			}
+			if err := t.command(next); err != nil {
+				return "", err
+			}
+		case '\n':
+			return string(line), nil
+		case '\r', 0:
+			continue
+		default:
+			line = append(line, b)
+		}
+	}
+}
+
+func (t *Telnet) command(cmd byte) error {
+	switch cmd {
+	case WILL, WONT, DO, DONT:
+		option, err := t.reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		t.negotiate(cmd, option)
+	case SB:
+		data, err := t.readSubnegotiation()
+		if err != nil {
+			return err
+		}
+		t.subnegotiate(data)
+	}
+	// Everything else (NOP, GA, AYT, ...) carries no state worth keeping.
+	return nil
+}
+
+func (t *Telnet) negotiate(cmd byte, option byte) {
+	switch cmd {
+	case WILL:
+		switch option {
+		case NAWS:
+		case TTYPE:
+			t.send(IAC, SB, TTYPE, SEND, IAC, SE)
+		default:
+			t.send(IAC, DONT, option)
+		}
+	case DO:
+		if option != ECHO || !t.isHidden() {
+			t.send(IAC, WONT, option)
+		}
+	}
+	// WONT and DONT are acknowledgements and need no answer.
+}
+
+func (t *Telnet) readSubnegotiation() ([]byte, error) {
+	data := make([]byte, 0)
+	for {
+		b, err := t.reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b != IAC {
+			data = append(data, b)
+			continue
+		}
+		next, err := t.reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if next == SE {
+			return data, nil
+		}
+		data = append(data, next)
+	}
+}
+
+func (t *Telnet) subnegotiate(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	switch data[0] {
+	case NAWS:
+		if len(data) < 5 {
+			return
+		}
+		width := int(data[1])<<8 | int(data[2])
+		height := int(data[3])<<8 | int(data[4])
+		t.session.SetWindowSize(width, height)
+	case TTYPE:
+		if len(data) < 2 || data[1] != IS {
+			return
+		}
+		t.session.SetTerminalType(string(data[2:]))
+	}
+}
+
+// HideInput tells the client the server will echo, which stops the client
+// from echoing locally.  The server then simply doesn't echo.
+func (t *Telnet) HideInput() {
+	t.mu.Lock()
+	t.hidden = true
+	t.mu.Unlock()
+	t.send(IAC, WILL, ECHO)
+}
+
+func (t *Telnet) ShowInput() {
+	t.mu.Lock()
+	t.hidden = false
+	t.mu.Unlock()
+	t.send(IAC, WONT, ECHO)
+}
+
+func (t *Telnet) isHidden() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.hidden
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"bytes"
+	"testing"
+)
+
+func newTestTelnet(input []byte) (*Session, *Telnet) {
+	s := New()
+	return s, NewTelnet(s, bytes.NewBuffer(input))
+}
+
+// written drains what the telnet layer queued for the client.
+func written(s *Session) []byte {
+	data := make([]byte, 0)
+	for len(s.Outgoing) > 0 {
+		data = append(data, <-s.Outgoing...)
+	}
+	return data
+}
+
+func TestTelnetStripsNegotiation(t *testing.T) {
+	input := []byte{IAC, WILL, NAWS, 'l', 'o', IAC, IAC, 'o', 'k', '\r', '\n'}
+	_, telnet := newTestTelnet(input)
+	line, err := telnet.ReadLine()
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if line != "lo\xffok" {
+		t.Fatalf("Unexpected line %q", line)
+	}
+}
+
+func TestTelnetWindowSize(t *testing.T) {
+	input := []byte{IAC, SB, NAWS, 0, 100, 0, 40, IAC, SE, '\n'}
+	s, telnet := newTestTelnet(input)
+	if s.Width() != DefaultWidth {
+		t.Fatalf("Unexpected default width %d", s.Width())
+	}
+	if _, err := telnet.ReadLine(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if s.Width() != 100 {
+		t.Fatalf("Width expected(100) actual(%d)", s.Width())
+	}
+}
+
+func TestTelnetTerminalType(t *testing.T) {
+	input := []byte{IAC, WILL, TTYPE, IAC, SB, TTYPE, IS, 'd', 'u', 'm', 'b', IAC, SE, '\n'}
+	s, telnet := newTestTelnet(input)
+	if _, err := telnet.ReadLine(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if reply := written(s); !bytes.Equal(reply, []byte{IAC, SB, TTYPE, SEND, IAC, SE}) {
+		t.Fatalf("Unexpected reply %v", reply)
+	}
+	if s.TerminalType() != "dumb" || s.SupportsColor() {
+		t.Fatalf("Unexpected terminal type %s", s.TerminalType())
+	}
+}
+
+func TestTelnetEcho(t *testing.T) {
+	input := []byte{IAC, DO, ECHO, '\n'}
+	s, telnet := newTestTelnet(input)
+	s.HideInput()
+	if _, err := telnet.ReadLine(); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if reply := written(s); !bytes.Equal(reply, []byte{IAC, WILL, ECHO}) {
+		t.Fatalf("Unexpected reply %v", reply)
+	}
+	if !IsCommand(string([]byte{IAC, WILL, ECHO})) || IsCommand("look") {
+		t.Fatalf("Negotiation not told apart from text")
+	}
+}
+
+func TestTelnetLineTooLong(t *testing.T) {
+	input := append(bytes.Repeat([]byte{'a'}, MaxLineLength+1), '\n')
+	_, telnet := newTestTelnet(input)
+	if _, err := telnet.ReadLine(); err != LineTooLong {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	_, telnet = newTestTelnet(append(bytes.Repeat([]byte{'a'}, MaxLineLength), '\n'))
+	if line, err := telnet.ReadLine(); err != nil || len(line) != MaxLineLength {
+		t.Fatalf("Unexpected error %v", err)
+	}
+}
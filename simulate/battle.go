@@ -3,8 +3,11 @@ package simulate
 import (
 	"github.com/michaelvmata/path/buffs"
 	"github.com/michaelvmata/path/events"
+	"github.com/michaelvmata/path/items"
+	"github.com/michaelvmata/path/skills"
 	"github.com/michaelvmata/path/world"
 	"math/rand"
+	"sort"
 	"strings"
 )
 
@@ -14,17 +17,24 @@ type Damage struct {
 	Critical bool
 }
 
-func CalculateHitDamage(attacker *world.Character, defender *world.Character) Damage {
-	weapon := attacker.Weapon()
+// RangePenalty is the share of damage lost when hitting a defender in
+// another room.
+const RangePenalty = 0.25
+
+func CalculateHitDamage(random *rand.Rand, attacker *world.Character, defender *world.Character) Damage {
+	return CalculateWeaponDamage(random, attacker, defender, attacker.Weapon())
+}
+
+func CalculateWeaponDamage(random *rand.Rand, attacker *world.Character, defender *world.Character, weapon *item.Weapon) Damage {
 	damage := Damage{
 		Type:     strings.ToLower(weapon.DamageType),
-		Amount:   rand.Intn(weapon.MaximumDamage-weapon.MinimumDamage) + weapon.MinimumDamage,
+		Amount:   random.Intn(weapon.MaximumDamage-weapon.MinimumDamage) + weapon.MinimumDamage,
 		Critical: false,
 	}
 
 	// Check if a critical hit
 	criticalRate := weapon.CriticalRate + (float64(attacker.Core.Agility.Value()) * .01)
-	if rand.Float64() <= criticalRate {
+	if random.Float64() <= criticalRate {
 		// Apply critical bonus
 		criticalBonus := weapon.CriticalBonus + (float64(attacker.Core.Insight.Value()) * .01)
 		damage.Amount = int(float64(damage.Amount) * (1.0 + criticalBonus))
@@ -33,25 +43,41 @@ func CalculateHitDamage(attacker *world.Character, defender *world.Character) Da
 
 	damage.Amount = damage.Amount + attacker.Core.Power.Value()
 
+	// Shots into another room lose some of their force
+	if attacker.Room != defender.Room {
+		damage.Amount = int(float64(damage.Amount) * (1.0 - RangePenalty))
+	}
+
 	// Apply adjustments
+	for _, buff := range attacker.Buffs {
+		if weaken, ok := buff.(*buffs.Weaken); ok && !buff.IsExpired() {
+			damage.Amount -= weaken.DamageReduction()
+		}
+	}
 	for _, buff := range defender.Buffs {
 		if barrier, ok := buff.(*buffs.Barrier); ok && !buff.IsExpired() {
 			damage.Amount -= barrier.DamageReduction()
 		}
 	}
+	if damage.Amount > 0 {
+		reduction := defender.Gear.ArmorReduction()
+		damage.Amount -= int(float64(damage.Amount) * reduction)
+		resistance := defender.Resistances.Of(damage.Type)
+		damage.Amount -= damage.Amount * resistance / 100
+	}
 	if damage.Amount < 0 {
 		damage.Amount = 0
 	}
 	return damage
 }
 
-func ShouldEvade(defender *world.Character) bool {
-	evasionLevel := defender.Skills.Evasion.Value()
+func ShouldEvade(random *rand.Rand, defender *world.Character) bool {
+	evasionLevel := defender.Skills.Level(skills.Evasion)
 	if evasionLevel <= 0 {
 		return false
 	}
 	evasionRate := float64(evasionLevel)*.01 + float64(defender.Core.Agility.Value())*.01
-	return rand.Float64() <= evasionRate
+	return random.Float64() <= evasionRate
 }
 
 func DoEvade(attacker *world.Character, defender *world.Character) {
@@ -59,8 +85,73 @@ func DoEvade(attacker *world.Character, defender *world.Character) {
 	defender.Showln("You evade %s.", attacker.Name)
 }
 
+// RiposteRatio is the share of a normal hit a riposte does.
+const RiposteRatio = 0.5
+
+// ShouldParry requires the defender to wield a weapon, bare hands can't
+// parry.
+func ShouldParry(random *rand.Rand, defender *world.Character) bool {
+	if defender.Gear.MainHand == nil {
+		return false
+	}
+	parryLevel := defender.Skills.Level(skills.Parry)
+	if parryLevel <= 0 {
+		return false
+	}
+	parryRate := float64(parryLevel)*.01 + float64(defender.Core.Power.Value())*.005
+	return random.Float64() <= parryRate
+}
+
+func DoParry(attacker *world.Character, defender *world.Character) {
+	attacker.Showln("%s parries your attack.", defender.Name)
+	defender.Showln("You parry %s's attack.", attacker.Name)
+}
+
+func ShouldRiposte(random *rand.Rand, defender *world.Character) bool {
+	parryLevel := defender.Skills.Level(skills.Parry)
+	if parryLevel <= 0 {
+		return false
+	}
+	riposteRate := float64(parryLevel)*.005 + float64(defender.Core.Agility.Value())*.005
+	return random.Float64() <= riposteRate
+}
+
+// DoRiposte strikes back after a parry and returns whether the attacker
+// died.
+func DoRiposte(random *rand.Rand, attacker *world.Character, defender *world.Character) bool {
+	damage := CalculateHitDamage(random, defender, attacker)
+	damage.Amount = int(float64(damage.Amount) * RiposteRatio)
+	defender.Showln("You riposte, doing <white>%d<reset> %s damage to %s.",
+		damage.Amount,
+		damage.Type,
+		attacker.Name)
+	attacker.Showln("%s ripostes, doing <white>%d<reset> %s damage to you.",
+		defender.Name,
+		damage.Amount,
+		damage.Type)
+	return DoDamage(defender, attacker, damage.Amount)
+}
+
+func ShouldBlock(random *rand.Rand, defender *world.Character) bool {
+	shield := defender.Gear.Shield()
+	if shield == nil {
+		return false
+	}
+	blockRate := shield.BlockRate + float64(defender.Core.Power.Value())*.005
+	return random.Float64() <= blockRate
+}
+
+func DoBlock(attacker *world.Character, defender *world.Character) {
+	shield := defender.Gear.Shield()
+	attacker.Showln("%s blocks your attack with %s.", defender.Name, shield.Name())
+	defender.Showln("You block %s's attack with %s.", attacker.Name, shield.Name())
+}
+
 func DoDamage(attacker *world.Character, defender *world.Character, amount int) bool {
 	defender.Health.Current -= amount
+	if attacker != defender {
+		defender.Threat.Add(attacker, amount)
+	}
 	dead := defender.IsDead()
 	if dead {
 		events.CharacterDeath.Emit(events.CharacterDeathPayload{
@@ -71,8 +162,43 @@ func DoDamage(attacker *world.Character, defender *world.Character, amount int)
 	return dead
 }
 
-func DoAttack(attacker *world.Character, defender *world.Character) bool {
-	damage := CalculateHitDamage(attacker, defender)
+// HealThreatRatio is the share of healing that provokes the healed
+// character's opponents.
+const HealThreatRatio = 0.5
+
+// DoHeal restores health up to the maximum.  Everyone fighting the healed
+// character takes note of the healer.
+func DoHeal(healer *world.Character, target *world.Character, amount int) int {
+	if missing := target.Health.Maximum - target.Health.Current; amount > missing {
+		amount = missing
+	}
+	if amount <= 0 {
+		return 0
+	}
+	target.Health.Current += amount
+	threat := int(float64(amount) * HealThreatRatio)
+	for _, opponent := range target.Room.Players {
+		if opponent.IsAttacking(target) {
+			opponent.Threat.Add(healer, threat)
+		}
+	}
+	return amount
+}
+
+func DoAttack(random *rand.Rand, attacker *world.Character, defender *world.Character) bool {
+	return DoWeaponAttack(attacker, defender, CalculateHitDamage(random, attacker, defender))
+}
+
+// OffHandRatio is the share of a normal hit an off hand attack does.
+const OffHandRatio = 0.5
+
+func DoOffHandAttack(random *rand.Rand, attacker *world.Character, defender *world.Character, weapon *item.Weapon) bool {
+	damage := CalculateWeaponDamage(random, attacker, defender, weapon)
+	damage.Amount = int(float64(damage.Amount) * OffHandRatio)
+	return DoWeaponAttack(attacker, defender, damage)
+}
+
+func DoWeaponAttack(attacker *world.Character, defender *world.Character, damage Damage) bool {
 	highlight := "white"
 	if damage.Critical {
 		highlight = "orange_3"
@@ -115,16 +241,47 @@ func Round(w *world.World, fighting map[string]*world.Character, attacker *world
 	if _, ok := fighting[attacker.UUID]; !ok {
 		attacker.Showln("")
 	}
+	if attacker.IsStunned() {
+		attacker.Showln("You are too stunned to fight.")
+		fighting[attacker.UUID] = attacker
+		return
+	}
+	attacker.ChooseTarget()
 
 	for _, defender := range attacker.Attacking {
 		if _, ok := fighting[defender.UUID]; !ok {
 			defender.Showln("")
 		}
-		for i := 1; i <= NumberOfAttacks(attacker); i++ {
-			if ShouldEvade(defender) {
+		attacks := NumberOfAttacks(attacker)
+		offHand := attacker.Gear.OffHandWeapon()
+		if attacker.IsSlowed() {
+			// Slowed characters lose half their attacks and their off hand
+			attacks = (attacks + 1) / 2
+			offHand = nil
+		}
+		if offHand != nil {
+			attacks++
+		}
+		// Stunned defenders can't avoid attacks
+		defends := !defender.IsStunned()
+		for i := 1; i <= attacks; i++ {
+			if defends && ShouldEvade(w.Random, defender) {
 				DoEvade(attacker, defender)
+			} else if defends && ShouldParry(w.Random, defender) {
+				DoParry(attacker, defender)
+				if ShouldRiposte(w.Random, defender) && DoRiposte(w.Random, attacker, defender) {
+					// Stop attacking after death
+					break
+				}
+			} else if defends && ShouldBlock(w.Random, defender) {
+				DoBlock(attacker, defender)
+			} else if offHand != nil && i == attacks {
+				// The off hand follows up after the main hand attacks
+				if DoOffHandAttack(w.Random, attacker, defender, offHand) {
+					break
+				}
 			} else {
-				if DoAttack(attacker, defender) {
+				if DoAttack(w.Random, attacker, defender) {
 					// Stop attacking after death
 					break
 				}
@@ -141,16 +298,61 @@ func Simulate(w *world.World) {
 	Buffs(w)
 }
 
+// HasteInitiative is the initiative each level of an active haste adds.
+const HasteInitiative = 5
+
+// SlowInitiative is the initiative a slowed character loses.
+const SlowInitiative = 10
+
+// Initiative decides how early a character acts in a battle tick.
+func Initiative(character *world.Character) int {
+	initiative := character.Core.Agility.Value()
+	for _, buff := range character.Buffs {
+		if haste, ok := buff.(*buffs.Haste); ok && !buff.IsExpired() {
+			initiative += haste.Level * HasteInitiative
+		}
+	}
+	if character.IsSlowed() {
+		initiative -= SlowInitiative
+	}
+	return initiative
+}
+
+// Combatants orders every character in the world by initiative.  Ties go to
+// players, by UUID, and then to mobiles in the order they spawned so the
+// same world always acts in the same order.
+func Combatants(w *world.World) []*world.Character {
+	combatants := make([]*world.Character, 0, len(w.Players)+len(w.Mobiles.Instances))
+	for _, player := range w.Players {
+		combatants = append(combatants, player)
+	}
+	sort.Slice(combatants, func(i, j int) bool {
+		return combatants[i].UUID < combatants[j].UUID
+	})
+	combatants = append(combatants, w.Mobiles.Instances...)
+
+	initiatives := make(map[*world.Character]int, len(combatants))
+	for _, combatant := range combatants {
+		initiatives[combatant] = Initiative(combatant)
+	}
+	sort.SliceStable(combatants, func(i, j int) bool {
+		return initiatives[combatants[i]] > initiatives[combatants[j]]
+	})
+	return combatants
+}
+
 func Battle(w *world.World) {
 	fighting := make(map[string]*world.Character)
-	for _, attacker := range w.Players {
-		Round(w, fighting, attacker)
-	}
-	for _, attacker := range w.Mobiles.Instances {
+	combatants := Combatants(w)
+	for _, attacker := range combatants {
 		Round(w, fighting, attacker)
 	}
-	for _, p := range fighting {
-		p.ShowNewline()
-		p.ShowPrompt()
+	for _, c := range combatants {
+		// Crowd control lasts whole battle rounds
+		c.Controls.Update()
+		if fighting[c.UUID] == c {
+			c.ShowNewline()
+			c.ShowPrompt()
+		}
 	}
 }
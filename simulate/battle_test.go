@@ -3,10 +3,16 @@ package simulate
 import (
 	"github.com/michaelvmata/path/buffs"
 	item "github.com/michaelvmata/path/items"
+	"github.com/michaelvmata/path/skills"
 	"github.com/michaelvmata/path/world"
+	"math/rand"
 	"testing"
 )
 
+func testRandom() *rand.Rand {
+	return rand.New(rand.NewSource(world.TestSeed))
+}
+
 func TestCalculateHitDamage(t *testing.T) {
 	attacker := world.NewPlayer("Test Attacker", "Test Handle")
 	w := item.NewWeapon("TestUUID", "Test Weapon", []string{"test"}, "", item.Crush, []string{item.Impact})
@@ -22,20 +28,20 @@ func TestCalculateHitDamage(t *testing.T) {
 
 	defender := world.NewPlayer("TestUUID2", "Test Defender")
 
-	if damage := CalculateHitDamage(attacker, defender); damage.Amount <= 0 || damage.Critical == true {
+	if damage := CalculateHitDamage(testRandom(), attacker, defender); damage.Amount <= 0 || damage.Critical == true {
 		t.Fatalf("Damage max(%d), min(%d), actual(%d)", w.MaximumDamage, w.MinimumDamage, damage.Amount)
 	}
 
 	w.CriticalRate = 1.0
 	w.CriticalBonus = 1.0
-	if damage := CalculateHitDamage(attacker, defender); damage.Amount <= 0 || damage.Critical == false {
+	if damage := CalculateHitDamage(testRandom(), attacker, defender); damage.Amount <= 0 || damage.Critical == false {
 		t.Fatalf("Damage max(%d), min(%d), actual(%d)", w.MaximumDamage*2.0, w.MinimumDamage*2.0, damage.Amount)
 	}
 }
 
 func TestNumberOfAttacks(t *testing.T) {
 	character := world.NewPlayer("Test UUID", "Test Handle")
-	character.Skills.Haste.Increment()
+	character.Skills.Get(buffs.HasteName).Increment()
 	buff := buffs.NewHaste(character)
 	character.Core.Will.Modify(buff.Upkeep())
 	character.Spirit.Maximum = buff.Upkeep()
@@ -52,11 +58,11 @@ func TestNumberOfAttacks(t *testing.T) {
 func TestEvade(t *testing.T) {
 	character := world.NewPlayer("Test UUID", "Test Handle")
 	character.Core.Agility.Increment()
-	if ShouldEvade(character) {
+	if ShouldEvade(testRandom(), character) {
 		t.Fatalf("Character evaded without skill")
 	}
-	character.Skills.Evasion.Modifier = 100
-	if !ShouldEvade(character) {
+	character.Skills.Get(skills.Evasion).Modifier = 100
+	if !ShouldEvade(testRandom(), character) {
 		t.Fatalf("Character with 100 evasion failed to evade")
 	}
 	DoEvade(character, character)
@@ -66,3 +72,285 @@ func TestDoDamage(t *testing.T) {
 	character := world.NewPlayer("Test UUID", "Test Handle")
 	DoDamage(character, character, 1)
 }
+
+func TestParry(t *testing.T) {
+	character := world.NewPlayer("Test UUID", "Test Handle")
+	character.Skills.Get(skills.Parry).Modifier = 100
+	if ShouldParry(testRandom(), character) {
+		t.Fatalf("Character parried without a weapon")
+	}
+	w := item.NewWeapon("TestUUID", "Test Weapon", []string{"test"}, "", item.Slash, []string{item.Blade})
+	character.Gear.Equip(w)
+	if !ShouldParry(testRandom(), character) {
+		t.Fatalf("Character with 100 parry failed to parry")
+	}
+	character.Skills.Get(skills.Parry).Modifier = 0
+	if ShouldParry(testRandom(), character) {
+		t.Fatalf("Character parried without skill")
+	}
+	DoParry(character, character)
+}
+
+func TestRiposte(t *testing.T) {
+	attacker := world.NewPlayer("Test Attacker", "Test Attacker")
+	attacker.Health.Maximum = 100
+	attacker.Health.Current = 100
+	defender := world.NewPlayer("Test Defender", "Test Defender")
+	if ShouldRiposte(testRandom(), defender) {
+		t.Fatalf("Character riposted without skill")
+	}
+	defender.Skills.Get(skills.Parry).Modifier = 200
+	if !ShouldRiposte(testRandom(), defender) {
+		t.Fatalf("Character with 200 parry failed to riposte")
+	}
+	DoRiposte(testRandom(), attacker, defender)
+	if attacker.Health.Current >= 100 {
+		t.Fatalf("Riposte did no damage")
+	}
+}
+
+func TestResistance(t *testing.T) {
+	attacker := world.NewPlayer("Test Attacker", "Test Handle")
+	w := item.NewWeapon("TestUUID", "Test Weapon", []string{"test"}, "", item.Crush, []string{item.Impact})
+	w.MaximumDamage = 101
+	w.MinimumDamage = 100
+	attacker.Gear.Equip(w)
+	attacker.Core.Power.Base = 0
+	attacker.Core.Agility.Base = 0
+
+	defender := world.NewPlayer("TestUUID2", "Test Defender")
+	defender.Resistances.Crush.Base = 50
+	if damage := CalculateHitDamage(testRandom(), attacker, defender); damage.Amount != 50 {
+		t.Fatalf("Resisted damage(%d) expected(50)", damage.Amount)
+	}
+	defender.Resistances.Crush.Base = 100
+	if damage := CalculateHitDamage(testRandom(), attacker, defender); damage.Amount != 25 {
+		t.Fatalf("Resistance not capped, damage(%d) expected(25)", damage.Amount)
+	}
+	defender.Resistances.Crush.Base = -50
+	if damage := CalculateHitDamage(testRandom(), attacker, defender); damage.Amount != 150 {
+		t.Fatalf("Vulnerable damage(%d) expected(150)", damage.Amount)
+	}
+}
+
+func TestArmor(t *testing.T) {
+	attacker := world.NewPlayer("Test Attacker", "Test Handle")
+	w := item.NewWeapon("TestUUID", "Test Weapon", []string{"test"}, "", item.Crush, []string{item.Impact})
+	w.MaximumDamage = 101
+	w.MinimumDamage = 100
+	attacker.Gear.Equip(w)
+	attacker.Core.Power.Base = 0
+	attacker.Core.Agility.Base = 0
+
+	defender := world.NewPlayer("TestUUID2", "Test Defender")
+	plate := item.NewArmor("Plate UUID", "test plate", item.Body, []string{"plate"}, "")
+	plate.Rating = item.ArmorScale
+	defender.Gear.Equip(plate)
+	if damage := CalculateHitDamage(testRandom(), attacker, defender); damage.Amount != 50 {
+		t.Fatalf("Armored damage(%d) expected(50)", damage.Amount)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	character := world.NewPlayer("Test UUID", "Test Handle")
+	if ShouldBlock(testRandom(), character) {
+		t.Fatalf("Character blocked without a shield")
+	}
+	shield := item.NewShield("Shield UUID", "test shield", []string{"shield"}, "")
+	shield.BlockRate = 1
+	character.Gear.Equip(shield)
+	if !ShouldBlock(testRandom(), character) {
+		t.Fatalf("Character with a certain block failed to block")
+	}
+	DoBlock(character, character)
+}
+
+func TestOffHandAttack(t *testing.T) {
+	attacker := world.NewPlayer("Test Attacker", "Test Handle")
+	attacker.Core.Power.Base = 0
+	attacker.Core.Agility.Base = 0
+	dagger := item.NewWeapon("Dagger UUID", "test dagger", []string{"dagger"}, "", item.Pierce, []string{item.Blade})
+	dagger.MaximumDamage = 101
+	dagger.MinimumDamage = 100
+	attacker.Gear.EquipIn(dagger, item.OffHand)
+
+	defender := world.NewPlayer("TestUUID2", "Test Defender")
+	defender.Health.Current = 1000
+	DoOffHandAttack(testRandom(), attacker, defender, dagger)
+	if defender.Health.Current != 950 {
+		t.Fatalf("Off hand health(%d) expected(950)", defender.Health.Current)
+	}
+}
+
+func TestCombatants(t *testing.T) {
+	w := world.NewWorld()
+	slow := world.NewPlayer("B UUID", "Slow")
+	slow.Core.Agility.Base = 1
+	tied := world.NewPlayer("A UUID", "Tied")
+	tied.Core.Agility.Base = 5
+	mobile := world.NewPlayer("Mobile UUID", "Mobile")
+	mobile.Core.Agility.Base = 5
+	hasted := world.NewPlayer("C UUID", "Hasted")
+	hasted.Core.Agility.Base = 1
+	hasted.Skills.Get(buffs.HasteName).Increment()
+	hasted.Apply(buffs.NewHaste(hasted))
+	w.Players["slow"] = slow
+	w.Players["tied"] = tied
+	w.Players["hasted"] = hasted
+	w.Mobiles.Instances = append(w.Mobiles.Instances, mobile)
+
+	if initiative := Initiative(hasted); initiative != 1+HasteInitiative {
+		t.Fatalf("Hasted initiative(%d) expected(%d)", initiative, 1+HasteInitiative)
+	}
+	expected := []*world.Character{hasted, tied, mobile, slow}
+	for n := 0; n < 10; n++ {
+		for i, combatant := range Combatants(w) {
+			if combatant != expected[i] {
+				t.Fatalf("Combatant %d is %s expected %s", i, combatant.Name, expected[i].Name)
+			}
+		}
+	}
+}
+
+func TestSeededBattle(t *testing.T) {
+	fight := func() []int {
+		w := world.NewWorld()
+		w.Seed(world.TestSeed)
+		attacker := world.NewPlayer("A UUID", "Attacker")
+		defender := world.NewPlayer("B UUID", "Defender")
+		for _, c := range []*world.Character{attacker, defender} {
+			weapon := item.NewWeapon("Sword UUID", "test sword", []string{"sword"}, "", item.Slash, []string{item.Blade})
+			weapon.MinimumDamage = 1
+			weapon.MaximumDamage = 20
+			weapon.CriticalRate = 0.2
+			c.Gear.Equip(weapon)
+			c.Skills.Get(skills.Evasion).Modifier = 20
+			c.Skills.Get(skills.Parry).Modifier = 20
+			c.Health.Maximum = 100000
+			c.Health.Current = 100000
+			w.Players[c.Name] = c
+		}
+		attacker.StartAttacking(defender)
+		defender.StartAttacking(attacker)
+
+		healths := make([]int, 0)
+		for i := 0; i < 20; i++ {
+			Battle(w)
+			healths = append(healths, attacker.Health.Current, defender.Health.Current)
+		}
+		return healths
+	}
+	first := fight()
+	second := fight()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Seeded battles diverged at %d: %v %v", i, first, second)
+		}
+	}
+}
+
+func TestThreatFromDamageAndHealing(t *testing.T) {
+	room := world.NewRoom("Room UUID", "Test Room", "", 10, nil)
+	mobile := world.NewPlayer("Mobile UUID", "Mobile")
+	tank := world.NewPlayer("Tank UUID", "Tank")
+	healer := world.NewPlayer("Healer UUID", "Healer")
+	for _, c := range []*world.Character{mobile, tank, healer} {
+		room.Enter(c)
+		c.Room = room
+		c.Health.Maximum = 1000
+		c.Health.Current = 1000
+	}
+	mobile.StartAttacking(tank)
+
+	DoDamage(tank, mobile, 100)
+	if mobile.Threat[tank] != 100 {
+		t.Fatalf("Damage threat(%d) expected(100)", mobile.Threat[tank])
+	}
+	tank.Health.Current = 500
+	if healed := DoHeal(healer, tank, 1000); healed != 500 || tank.Health.Current != 1000 {
+		t.Fatalf("Healed(%d) past maximum health", healed)
+	}
+	if mobile.Threat[healer] != 250 {
+		t.Fatalf("Healing threat(%d) expected(250)", mobile.Threat[healer])
+	}
+}
+
+func TestControlledRound(t *testing.T) {
+	w := world.NewWorld()
+	w.Seed(world.TestSeed)
+	attacker := world.NewPlayer("A UUID", "Attacker")
+	defender := world.NewPlayer("B UUID", "Defender")
+	defender.Health.Current = 1000
+	attacker.StartAttacking(defender)
+
+	attacker.Control(world.Stun, 1)
+	Round(w, make(map[string]*world.Character), attacker)
+	if defender.Health.Current != 1000 {
+		t.Fatalf("Stunned attacker swung")
+	}
+
+	attacker.Controls = make(world.Controls)
+	attacker.Control(world.Slow, 1)
+	if initiative := Initiative(attacker); initiative != attacker.Core.Agility.Value()-SlowInitiative {
+		t.Fatalf("Slowed initiative(%d) expected(%d)", initiative, attacker.Core.Agility.Value()-SlowInitiative)
+	}
+	Round(w, make(map[string]*world.Character), attacker)
+	if defender.Health.Current == 1000 {
+		t.Fatalf("Slowed attacker didn't swing")
+	}
+}
+
+func TestStunLastsABattleRound(t *testing.T) {
+	w := world.NewWorld()
+	w.Seed(world.TestSeed)
+	room := world.NewRoom("Room UUID", "Test Room", "", 10, nil)
+	attacker := world.NewPlayer("A UUID", "Attacker")
+	defender := world.NewPlayer("B UUID", "Defender")
+	defender.Core.Power.Base = 100
+	defender.Health.Current = 10000
+	for _, c := range []*world.Character{attacker, defender} {
+		room.Enter(c)
+		c.Room = room
+		w.Players[c.Name] = c
+	}
+	attacker.StartAttacking(defender)
+	tick := func() {
+		w.Update()
+		if w.IsBattleTick() {
+			Simulate(w)
+		}
+	}
+
+	// Stunned between battle rounds, like by a bash
+	attacker.Control(world.Stun, 1)
+	for !w.IsBattleTick() || w.Ticks == 0 {
+		tick()
+	}
+	if defender.Health.Current != 10000 {
+		t.Fatalf("Stunned attacker swung in the next battle round")
+	}
+	tick()
+	for !w.IsBattleTick() {
+		tick()
+	}
+	if defender.Health.Current == 10000 {
+		t.Fatalf("Stun outlasted its battle round")
+	}
+}
+
+func TestWeaken(t *testing.T) {
+	attacker := world.NewPlayer("Test Attacker", "Test Handle")
+	w := item.NewWeapon("TestUUID", "Test Weapon", []string{"test"}, "", item.Crush, []string{item.Impact})
+	w.MaximumDamage = 101
+	w.MinimumDamage = 100
+	attacker.Gear.Equip(w)
+	attacker.Core.Power.Base = 0
+	attacker.Core.Agility.Base = 0
+	defender := world.NewPlayer("TestUUID2", "Test Defender")
+
+	attacker.Apply(buffs.NewWeaken(attacker, defender))
+	attacker.Apply(buffs.NewWeaken(attacker, defender))
+	if damage := CalculateHitDamage(testRandom(), attacker, defender); damage.Amount != 96 {
+		t.Fatalf("Weakened damage(%d) expected(96)", damage.Amount)
+	}
+}
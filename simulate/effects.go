@@ -1,38 +1,82 @@
 package simulate
 
 import (
-	"fmt"
-	"github.com/michaelvmata/path/buffs"
 	"github.com/michaelvmata/path/world"
+	"log"
 )
 
 func Buffs(w *world.World) {
 	for _, player := range w.Players {
 		DoBuffs(player)
 	}
-	for _, mobile := range w.Mobiles.Instances {
+	// Mobiles killed by their buffs leave the instances
+	mobiles := append([]*world.Character{}, w.Mobiles.Instances...)
+	for _, mobile := range mobiles {
 		DoBuffs(mobile)
 	}
 }
 
+// DoBuffs ticks every periodic buff on the character until one kills it.
 func DoBuffs(character *world.Character) {
 	for _, buff := range character.Buffs {
-		switch buff := buff.(type) {
-		case *buffs.Bleed:
-			DoBleed(buff)
+		ticker, ok := buff.(world.Ticker)
+		if !ok || buff.IsExpired() {
+			continue
+		}
+		if DoTick(character, ticker.Tick()) {
+			return
+		}
+	}
+}
+
+// DoTick applies one tick of a periodic effect and returns whether the
+// character died.
+func DoTick(character *world.Character, tick world.Tick) bool {
+	showTick(character, tick.Message)
+	if tick.Heal > 0 {
+		healed := DoHeal(tick.Source, character, tick.Heal)
+		if tick.Healed != nil && healed > 0 {
+			showTick(character, tick.Healed(healed))
+		}
+	}
+	if tick.Spirit != 0 {
+		spirit := character.Spirit.Current
+		character.Spirit.Current += tick.Spirit
+		character.Spirit.EnforceMaximum()
+		if character.Spirit.Current < 0 {
+			character.Spirit.Current = 0
+		}
+		if drained := spirit - character.Spirit.Current; drained > 0 {
+			DoDrain(tick, character, drained)
 		}
 	}
+	if tick.Damage > 0 {
+		return DoDamage(tick.Source, character, tick.Damage)
+	}
+	return false
+}
+
+// DoDrain hands spirit drained by a tick to its source when the tick
+// absorbs it.
+func DoDrain(tick world.Tick, character *world.Character, drained int) {
+	if tick.Absorb && tick.Source != nil && !tick.Source.IsDead() {
+		tick.Source.Spirit.Current += drained
+		tick.Source.Spirit.EnforceMaximum()
+	}
+	if tick.Drained != nil {
+		showTick(character, tick.Drained(drained))
+	}
 }
 
-func DoBleed(bleed *buffs.Bleed) {
-	damageAmount := 1000
-	message := world.Message{
-		FirstPerson:         bleed.Applier,
-		FirstPersonMessage:  fmt.Sprintf("%s takes %d bleed damage from the wound you inflicted.", bleed.Character.Name, damageAmount),
-		SecondPerson:        bleed.Character,
-		SecondPersonMessage: fmt.Sprintf("You take %d bleed damage.", damageAmount),
-		ThirdPersonMessage:  fmt.Sprintf("%s takes %d bleed damage.", bleed.Character.Name, damageAmount),
-	}
-	bleed.Character.Room.ShowMessage(message)
-	DoDamage(bleed.Applier, bleed.Character, damageAmount)
+func showTick(character *world.Character, message world.Message) {
+	if message.FirstPerson != nil && message.FirstPerson.Room != character.Room {
+		// The source moved on, like a wounded opponent that fled
+		message.FirstPerson.Showln(message.FirstPersonMessage)
+		message.FirstPerson = nil
+	}
+	if character.Room != nil {
+		if err := character.Room.ShowMessage(message); err != nil {
+			log.Printf("Problem showing tick message: %v", err)
+		}
+	}
 }
@@ -0,0 +1,88 @@
+package simulate
+
+import (
+	"github.com/michaelvmata/path/buffs"
+	"github.com/michaelvmata/path/session"
+	"github.com/michaelvmata/path/world"
+	"testing"
+)
+
+// tickBuff is a periodic effect simulate knows nothing about.
+type tickBuff struct {
+	buffs.Effect
+	ticks int
+}
+
+func (t *tickBuff) ApplyMessage() string   { return "tick" }
+func (t *tickBuff) UnapplyMessage() string { return "tick" }
+func (t *tickBuff) AlreadyApplied() string { return "tick" }
+func (t *tickBuff) Upkeep() int            { return 1 }
+func (t *tickBuff) Tick() world.Tick {
+	t.ticks++
+	return world.Tick{}
+}
+
+func TestDoBuffs(t *testing.T) {
+	room := world.NewRoom("Room UUID", "Test Room", "", 10, nil)
+	applier := world.NewPlayer("Applier UUID", "Applier")
+	character := world.NewPlayer("Character UUID", "Character")
+	for _, c := range []*world.Character{applier, character} {
+		room.Enter(c)
+		c.Room = room
+		c.Health.Maximum = 1000
+		c.Health.Current = 500
+		c.Spirit.Maximum = 1000
+		c.Spirit.Current = 500
+	}
+	applier.Core.Power.Base = 10
+	applier.Core.Insight.Base = 5
+	applier.Skills.Get(buffs.BleedName).Base = 2
+
+	character.Apply(buffs.NewBleed(character, applier))
+	character.Apply(buffs.NewSiphon(character, applier))
+	generic := &tickBuff{Effect: buffs.NewEffect(5, "tick", world.Stacking{Rule: world.Refresh})}
+	character.Apply(generic)
+	DoBuffs(character)
+	if character.Health.Current != 470 || character.Threat[applier] != 30 {
+		t.Fatalf("Bleed health(%d) expected(470)", character.Health.Current)
+	}
+	if character.Spirit.Current != 495 || applier.Spirit.Current != 505 {
+		t.Fatalf("Siphon spirit(%d, %d) expected(495, 505)", character.Spirit.Current, applier.Spirit.Current)
+	}
+	if generic.ticks != 1 {
+		t.Fatalf("Generic ticker not ticked")
+	}
+
+	character.Dispel(2, world.Harmful)
+	character.Core.Will.Base = 20
+	character.Apply(buffs.NewRegenerate(character, character))
+	DoBuffs(character)
+	if character.Health.Current != 470+character.Core.Will.Value() {
+		t.Fatalf("Regenerate health(%d) expected(%d)", character.Health.Current, 470+character.Core.Will.Value())
+	}
+}
+
+func TestRegenerateMessage(t *testing.T) {
+	room := world.NewRoom("Room UUID", "Test Room", "", 10, nil)
+	character := world.NewPlayer("Character UUID", "Character")
+	room.Enter(character)
+	character.Room = room
+	character.Session = session.New()
+	character.Health.Maximum = 1000
+	character.Health.Current = 995
+	character.Core.Will.Base = 20
+
+	character.Apply(buffs.NewRegenerate(character, character))
+	for len(character.Session.Outgoing) > 0 {
+		<-character.Session.Outgoing
+	}
+	DoBuffs(character)
+	if message := <-character.Session.Outgoing; message != "You regenerate 5 health." {
+		t.Fatalf("Regenerate message(%s) expected actual heal of 5", message)
+	}
+	<-character.Session.Outgoing
+	DoBuffs(character)
+	if len(character.Session.Outgoing) > 0 {
+		t.Fatalf("Regenerate message shown at full health")
+	}
+}
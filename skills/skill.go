@@ -4,47 +4,220 @@ import (
 	"fmt"
 	"github.com/michaelvmata/path/stats"
 	"github.com/michaelvmata/path/symbols"
+	"gopkg.in/yaml.v3"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
-type Skills struct {
-	Barrier  stats.Stat
-	Backstab stats.Stat
-	Bash     stats.Stat
-	Bleed    stats.Stat
-	Blitz    stats.Stat
-	Circle   stats.Stat
-	Evasion  stats.Stat
-	Haste    stats.Stat
-	Parry    stats.Stat
-	Sweep    stats.Stat
-}
-
-func (s Skills) Describe() string {
-	parts := []string{
-		fmt.Sprintf("%s Backstab: %d", symbols.TRIANGULAR_BULLET, s.Backstab.Value()),
-		fmt.Sprintf("%s Barrier: %d", symbols.TRIANGULAR_BULLET, s.Barrier.Value()),
-		fmt.Sprintf("%s Bash: %d", symbols.TRIANGULAR_BULLET, s.Bash.Value()),
-		fmt.Sprintf("%s Bleed: %d", symbols.TRIANGULAR_BULLET, s.Bleed.Value()),
-		fmt.Sprintf("%s Blitz: %d", symbols.TRIANGULAR_BULLET, s.Blitz.Value()),
-		fmt.Sprintf("%s Circle: %d", symbols.TRIANGULAR_BULLET, s.Circle.Value()),
-		fmt.Sprintf("%s Evasion: %d", symbols.TRIANGULAR_BULLET, s.Evasion.Value()),
-		fmt.Sprintf("%s Haste: %d", symbols.TRIANGULAR_BULLET, s.Haste.Value()),
-		fmt.Sprintf("%s Parry: %d", symbols.TRIANGULAR_BULLET, s.Parry.Value()),
-		fmt.Sprintf("%s Sweep: %d", symbols.TRIANGULAR_BULLET, s.Sweep.Value()),
+// Skills used directly by the combat simulation.
+const (
+	Evasion = "evasion"
+	Parry   = "parry"
+)
+
+// Scale is a value that grows with the skill's level.
+type Scale struct {
+	Base     int `yaml:"Base"`
+	PerLevel int `yaml:"PerLevel"`
+}
+
+func (s Scale) At(level int) int {
+	return s.Base + s.PerLevel*level
+}
+
+// Cost is the essence needed to raise a skill from a level, Growth to the
+// power of the level plus PerLevel for each level.
+type Cost struct {
+	Growth   float64 `yaml:"Growth"`
+	PerLevel int     `yaml:"PerLevel"`
+}
+
+func (c Cost) At(level int) int {
+	return int(math.Pow(c.Growth, float64(level))) + c.PerLevel*level
+}
+
+// Targets other than a single defender.  Opponents is everyone the
+// attacker is fighting and Self is the attacker, without starting a fight.
+const (
+	Opponents = "opponents"
+	Self      = "self"
+)
+
+// Messages are shown to the attacker, the defender and the room.  $n is
+// replaced with the attacker's name, $N with the defender's and $d with the
+// damage done.
+type Messages struct {
+	First  string `yaml:"First"`
+	Second string `yaml:"Second"`
+	Third  string `yaml:"Third"`
+}
+
+// Damage is Hit percent of a regular hit plus Flat, dealt Repeat times.
+type Damage struct {
+	Hit    Scale `yaml:"Hit"`
+	Flat   Scale `yaml:"Flat"`
+	Repeat Scale `yaml:"Repeat"`
+}
+
+func (d Damage) Amount(level int, hit int) int {
+	return hit*d.Hit.At(level)/100 + d.Flat.At(level)
+}
+
+// Times is how often the damage is dealt, at least once.
+func (d Damage) Times(level int) int {
+	if times := d.Repeat.At(level); times > 1 {
+		return times
 	}
-	return strings.Join(parts, "\n")
+	return 1
+}
+
+// Effect is one step of a technique.  It sets one of Damage, a crowd
+// control's ticks, Buff, Memory or Threat and may show a Message as it
+// happens.
+type Effect struct {
+	Damage  *Damage   `yaml:"Damage"`
+	Stun    int       `yaml:"Stun"`
+	Root    int       `yaml:"Root"`
+	Silence int       `yaml:"Silence"`
+	Slow    int       `yaml:"Slow"`
+	Buff    string    `yaml:"Buff"`
+	Memory  int       `yaml:"Memory"`
+	Threat  *Scale    `yaml:"Threat"`
+	Message *Messages `yaml:"Message"`
+}
+
+func (e Effect) primitives() int {
+	count := 0
+	for _, set := range []bool{e.Damage != nil, e.Stun > 0, e.Root > 0, e.Silence > 0, e.Slow > 0, e.Buff != "", e.Memory > 0, e.Threat != nil} {
+		if set {
+			count++
+		}
+	}
+	return count
+}
+
+// Definition describes a skill loaded from the skills data directory.
+// Skills with Effects are techniques used as commands; Opener techniques
+// can only start a fight, and Expected is shown instead of the effects when
+// the defender remembers the technique as the last thing done to them.
+type Definition struct {
+	Name     string    `yaml:"Name"`
+	Cost     Cost      `yaml:"Cost"`
+	Spirit   Scale     `yaml:"Spirit"`
+	CoolDown Scale     `yaml:"CoolDown"`
+	Weapon   string    `yaml:"Weapon"`
+	Invest   string    `yaml:"Invest"`
+	Keywords []string  `yaml:"Keywords"`
+	Help     string    `yaml:"Help"`
+	Target   string    `yaml:"Target"`
+	Opener   bool      `yaml:"Opener"`
+	Expected *Messages `yaml:"Expected"`
+	Effects  []Effect  `yaml:"Effects"`
+}
+
+func (d Definition) IsTechnique() bool {
+	return len(d.Effects) > 0
+}
+
+// Key is the lower case name skills are looked up and saved by.
+func (d Definition) Key() string {
+	return strings.ToLower(d.Name)
+}
+
+// Registry holds every skill definition by key.
+type Registry map[string]Definition
+
+// Names lists the skill keys alphabetically.
+func (r Registry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func buildFromPath(path string) Definition {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Error reading skill YAML file %s", path)
+	}
+	d := Definition{}
+	if err := yaml.Unmarshal(data, &d); err != nil {
+		log.Fatalf("Error parsing skill file %s: %v", path, err)
+	}
+	if d.Name == "" {
+		log.Fatalf("Missing Name: %s", path)
+	}
+	if d.Cost.Growth < 1 {
+		log.Fatalf("Cost Growth below 1: %s", path)
+	}
+	if d.Help == "" {
+		log.Fatalf("Missing Help: %s", path)
+	}
+	if d.Target != "" && d.Target != Opponents && d.Target != Self {
+		log.Fatalf("Unknown Target %s: %s", d.Target, path)
+	}
+	for i, effect := range d.Effects {
+		if count := effect.primitives(); count > 1 || (count == 0 && effect.Message == nil) {
+			log.Fatalf("Effect %d needs one of Damage, Stun, Root, Silence, Slow, Buff, Memory or Threat: %s", i, path)
+		}
+	}
+	if d.Invest == "" {
+		d.Invest = fmt.Sprintf("Your mastery of %s improves.", d.Key())
+	}
+	return d
 }
 
+func Build(root string) Registry {
+	registry := make(Registry)
+	paths, err := filepath.Glob(filepath.Join(root, "*.yaml"))
+	if err != nil {
+		log.Fatalf("Error reading skills directory %s", root)
+	}
+	for _, path := range paths {
+		d := buildFromPath(path)
+		if _, ok := registry[d.Key()]; ok {
+			log.Fatalf("Duplicate skill %s: %s", d.Name, path)
+		}
+		registry[d.Key()] = d
+	}
+	return registry
+}
+
+// Skills are a character's proficiencies by skill key.
+type Skills map[string]*stats.Stat
+
 func NewSkills() Skills {
-	return Skills{
-		Backstab: stats.NewStat(0, 0),
-		Bash:     stats.NewStat(0, 0),
-		Bleed:    stats.NewStat(0, 0),
-		Blitz:    stats.NewStat(0, 0),
-		Circle:   stats.NewStat(0, 0),
-		Evasion:  stats.NewStat(0, 0),
-		Haste:    stats.NewStat(0, 0),
-		Parry:    stats.NewStat(0, 0),
+	return make(Skills)
+}
+
+// Get returns the skill, adding it untrained when the character has never
+// invested in it.
+func (s Skills) Get(name string) *stats.Stat {
+	stat, ok := s[name]
+	if !ok {
+		stat = &stats.Stat{}
+		s[name] = stat
 	}
+	return stat
+}
+
+func (s Skills) Level(name string) int {
+	if stat, ok := s[name]; ok {
+		return stat.Value()
+	}
+	return 0
+}
+
+func (s Skills) Describe(registry Registry) string {
+	parts := make([]string, 0, len(registry))
+	for _, name := range registry.Names() {
+		parts = append(parts, fmt.Sprintf("%s %s: %d", symbols.TRIANGULAR_BULLET, registry[name].Name, s.Level(name)))
+	}
+	return strings.Join(parts, "\n")
 }
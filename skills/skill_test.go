@@ -1,10 +1,42 @@
 package skills
 
 import (
+	"strings"
 	"testing"
 )
 
 func TestSkill(t *testing.T) {
 	s := NewSkills()
-	s.Describe()
+	if s.Level(Parry) != 0 {
+		t.Fatalf("Untrained skill has a level")
+	}
+	s.Get(Parry).Increment()
+	if s.Level(Parry) != 1 {
+		t.Fatalf("Skill level expected(1) actual(%d)", s.Level(Parry))
+	}
+	registry := Registry{Parry: Definition{Name: "Parry"}, Evasion: Definition{Name: "Evasion"}}
+	if description := s.Describe(registry); !strings.Contains(description, "Parry: 1") || !strings.Contains(description, "Evasion: 0") {
+		t.Fatalf("Skills description missing skills %s", description)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	registry := Build("../data/skills")
+	parry, ok := registry[Parry]
+	if !ok {
+		t.Fatalf("Parry not loaded")
+	}
+	if cost := parry.Cost.At(0); cost != 1 {
+		t.Fatalf("Parry cost expected(1) actual(%d)", cost)
+	}
+	if backstab := registry["backstab"]; backstab.Weapon != "blade" || backstab.CoolDown.At(3) != 3 {
+		t.Fatalf("Backstab definition not loaded %v", backstab)
+	}
+	blitz := registry["blitz"]
+	if !blitz.IsTechnique() || blitz.Effects[1].Damage.Times(2) != 3 || blitz.Effects[1].Damage.Amount(2, 100) != 12 {
+		t.Fatalf("Blitz effects not loaded %v", blitz.Effects)
+	}
+	if registry[Parry].IsTechnique() {
+		t.Fatalf("Parry loaded as a technique")
+	}
 }
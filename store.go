@@ -0,0 +1,132 @@
+package main
+
+import (
+	"github.com/michaelvmata/path/world"
+	"gopkg.in/yaml.v3"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Store is the persistence backend.  Areas are authored content and are
+// only ever loaded, while characters are loaded at boot and saved as they
+// change.
+type Store interface {
+	LoadAreas(*world.World) error
+	ListCharacters() ([]string, error)
+	LoadCharacter(*world.World, string) (*world.Character, error)
+	SaveCharacter(*world.Character) error
+	Close() error
+}
+
+// YAMLStore keeps each character in its own YAML file named by UUID.
+type YAMLStore struct {
+	AreasPath   string
+	PlayersPath string
+}
+
+func NewYAMLStore(areasPath string, playersPath string) *YAMLStore {
+	return &YAMLStore{
+		AreasPath:   areasPath,
+		PlayersPath: playersPath,
+	}
+}
+
+func (s *YAMLStore) LoadAreas(w *world.World) error {
+	buildAreas(w, s.AreasPath)
+	return nil
+}
+
+func (s *YAMLStore) ListCharacters() ([]string, error) {
+	UUIDs := make([]string, 0)
+	nodes, err := os.ReadDir(s.PlayersPath)
+	if os.IsNotExist(err) {
+		return UUIDs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range nodes {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".yaml" {
+			continue
+		}
+		UUIDs = append(UUIDs, strings.TrimSuffix(f.Name(), ".yaml"))
+	}
+	return UUIDs, nil
+}
+
+func (s *YAMLStore) LoadCharacter(w *world.World, UUID string) (*world.Character, error) {
+	data, err := os.ReadFile(playerPath(s.PlayersPath, UUID))
+	if err != nil {
+		return nil, err
+	}
+	rp, err := migrateCharacter(data)
+	if err != nil {
+		return nil, err
+	}
+	return buildPlayer(w, rp), nil
+}
+
+func (s *YAMLStore) SaveCharacter(player *world.Character) error {
+	data, err := yaml.Marshal(yamlFromPlayer(player))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.PlayersPath, 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(playerPath(s.PlayersPath, player.UUID), data); err != nil {
+		return err
+	}
+	log.Printf("Wrote %d bytes to %s player file.", len(data), player.Name)
+	return nil
+}
+
+func (s *YAMLStore) Close() error {
+	return nil
+}
+
+func playerPath(root string, UUID string) string {
+	return filepath.Join(root, UUID+".yaml")
+}
+
+// writeFileAtomic replaces path with data without ever leaving a partially
+// written file behind.  The data is written and synced to a temporary file
+// in the same directory, which is then renamed over the original.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	temp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return err
+	}
+	// Sync the directory so the rename itself survives a crash.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+func saveCharacters(store Store, players map[string]*world.Character) {
+	for _, player := range players {
+		if err := store.SaveCharacter(player); err != nil {
+			log.Printf("Unable to save player %s: %v", player.Name, err)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"database/sql"
+	"github.com/michaelvmata/path/world"
+	"log"
+	_ "modernc.org/sqlite"
+)
+
+// sqlMigrations are applied in order and never edited once released.  Add
+// a new entry to change the schema.
+var sqlMigrations = []string{
+	`CREATE TABLE characters (
+		uuid TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		description TEXT NOT NULL DEFAULT '',
+		essence INTEGER NOT NULL DEFAULT 0,
+		power INTEGER NOT NULL DEFAULT 1,
+		agility INTEGER NOT NULL DEFAULT 1,
+		insight INTEGER NOT NULL DEFAULT 1,
+		will INTEGER NOT NULL DEFAULT 1,
+		health INTEGER NOT NULL DEFAULT 0,
+		spirit INTEGER NOT NULL DEFAULT 0,
+		room_uuid TEXT NOT NULL DEFAULT '',
+		anchor_uuid TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE TABLE character_gear (
+		character_uuid TEXT NOT NULL REFERENCES characters(uuid) ON DELETE CASCADE,
+		slot TEXT NOT NULL,
+		item_uuid TEXT NOT NULL,
+		PRIMARY KEY (character_uuid, slot)
+	)`,
+	`CREATE TABLE character_inventory (
+		character_uuid TEXT NOT NULL REFERENCES characters(uuid) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		item_uuid TEXT NOT NULL,
+		PRIMARY KEY (character_uuid, position)
+	)`,
+	`CREATE TABLE character_skills (
+		character_uuid TEXT NOT NULL REFERENCES characters(uuid) ON DELETE CASCADE,
+		skill TEXT NOT NULL,
+		level INTEGER NOT NULL,
+		PRIMARY KEY (character_uuid, skill)
+	)`,
+	`CREATE TABLE character_quests (
+		character_uuid TEXT NOT NULL REFERENCES characters(uuid) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		quest_uuid TEXT NOT NULL,
+		step INTEGER NOT NULL,
+		current INTEGER NOT NULL,
+		PRIMARY KEY (character_uuid, position, step)
+	)`,
+	`CREATE TABLE character_items (
+		character_uuid TEXT NOT NULL REFERENCES characters(uuid) ON DELETE CASCADE,
+		instance_id TEXT PRIMARY KEY,
+		item_uuid TEXT NOT NULL,
+		slot TEXT NOT NULL DEFAULT '',
+		position INTEGER NOT NULL
+	)`,
+	`INSERT INTO character_items (character_uuid, instance_id, item_uuid, slot, position)
+		SELECT character_uuid, lower(hex(randomblob(16))), item_uuid, slot, 0 FROM character_gear`,
+	`INSERT INTO character_items (character_uuid, instance_id, item_uuid, slot, position)
+		SELECT character_uuid, lower(hex(randomblob(16))), item_uuid, '', position FROM character_inventory`,
+	`DROP TABLE character_gear`,
+	`DROP TABLE character_inventory`,
+	`UPDATE character_skills SET skill = lower(skill)`,
+	`ALTER TABLE character_items ADD COLUMN condition INTEGER NOT NULL DEFAULT 100`,
+	`ALTER TABLE characters ADD COLUMN resist_crush INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE characters ADD COLUMN resist_pierce INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE characters ADD COLUMN resist_slash INTEGER NOT NULL DEFAULT 0`,
+}
+
+// SQLStore keeps characters in an embedded SQLite database.  Areas are
+// still authored as YAML and loaded from AreasPath.
+type SQLStore struct {
+	AreasPath string
+	db        *sql.DB
+}
+
+func NewSQLStore(areasPath string, dataSource string) (*SQLStore, error) {
+	db, err := sql.Open("sqlite", dataSource)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only supports one writer, and in memory databases are per
+	// connection.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	s := &SQLStore{AreasPath: areasPath, db: db}
+	if err := s.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLStore) Version() (int, error) {
+	if _, err := s.db.Exec("CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)"); err != nil {
+		return 0, err
+	}
+	var version int
+	err := s.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	return version, err
+}
+
+func (s *SQLStore) Migrate() error {
+	version, err := s.Version()
+	if err != nil {
+		return err
+	}
+	for ; version < len(sqlMigrations); version++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqlMigrations[version]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO schema_version (version) VALUES (?)", version+1); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		log.Printf("Migrated database to version %d", version+1)
+	}
+	return nil
+}
+
+func (s *SQLStore) LoadAreas(w *world.World) error {
+	buildAreas(w, s.AreasPath)
+	return nil
+}
+
+func (s *SQLStore) ListCharacters() ([]string, error) {
+	rows, err := s.db.Query("SELECT uuid FROM characters ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	UUIDs := make([]string, 0)
+	for rows.Next() {
+		var UUID string
+		if err := rows.Scan(&UUID); err != nil {
+			return nil, err
+		}
+		UUIDs = append(UUIDs, UUID)
+	}
+	return UUIDs, rows.Err()
+}
+
+func (s *SQLStore) LoadCharacter(w *world.World, UUID string) (*world.Character, error) {
+	rp := YAMLMobile{Version: CharacterVersion, UUID: UUID, Skills: make(map[string]int)}
+	err := s.db.QueryRow(`SELECT name, description, essence, power, agility, insight, will,
+		health, spirit, room_uuid, anchor_uuid, resist_crush, resist_pierce, resist_slash
+		FROM characters WHERE uuid = ?`, UUID).Scan(
+		&rp.Name, &rp.Description, &rp.Essence, &rp.Power, &rp.Agility, &rp.Insight, &rp.Will,
+		&rp.Health, &rp.Spirit, &rp.RoomUUID, &rp.Anchor,
+		&rp.Resistances.Crush, &rp.Resistances.Pierce, &rp.Resistances.Slash)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`SELECT item_uuid, instance_id, slot, condition FROM character_items
+		WHERE character_uuid = ? ORDER BY position`, UUID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		yi := YAMLItemInstance{}
+		if err := rows.Scan(&yi.UUID, &yi.InstanceID, &yi.Slot, &yi.Condition); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rp.Items = append(rp.Items, yi)
+	}
+	rows.Close()
+
+	rows, err = s.db.Query("SELECT skill, level FROM character_skills WHERE character_uuid = ?", UUID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var skill string
+		var level int
+		if err := rows.Scan(&skill, &level); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rp.Skills[skill] = level
+	}
+	rows.Close()
+
+	rows, err = s.db.Query(`SELECT position, quest_uuid, current FROM character_quests
+		WHERE character_uuid = ? ORDER BY position, step`, UUID)
+	if err != nil {
+		return nil, err
+	}
+	position := -1
+	for rows.Next() {
+		var current, next int
+		var questUUID string
+		if err := rows.Scan(&next, &questUUID, &current); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if next != position {
+			position = next
+			rp.Quests = append(rp.Quests, YAMLMobileQuests{UUID: questUUID})
+		}
+		q := &rp.Quests[len(rp.Quests)-1]
+		q.Steps = append(q.Steps, YAMLMobileQuestStep{Current: current})
+	}
+	rows.Close()
+
+	return buildPlayer(w, rp), nil
+}
+
+func (s *SQLStore) SaveCharacter(player *world.Character) error {
+	p := yamlFromPlayer(player)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO characters (uuid, name, description, essence, power, agility,
+		insight, will, health, spirit, room_uuid, anchor_uuid, resist_crush, resist_pierce, resist_slash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(uuid) DO UPDATE SET name = excluded.name, description = excluded.description,
+		essence = excluded.essence, power = excluded.power, agility = excluded.agility,
+		insight = excluded.insight, will = excluded.will, health = excluded.health,
+		spirit = excluded.spirit, room_uuid = excluded.room_uuid, anchor_uuid = excluded.anchor_uuid,
+		resist_crush = excluded.resist_crush, resist_pierce = excluded.resist_pierce,
+		resist_slash = excluded.resist_slash`,
+		p.UUID, p.Name, p.Description, p.Essence, p.Power, p.Agility,
+		p.Insight, p.Will, p.Health, p.Spirit, p.RoomUUID, p.Anchor,
+		p.Resistances.Crush, p.Resistances.Pierce, p.Resistances.Slash)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, table := range []string{"character_items", "character_skills", "character_quests"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE character_uuid = ?", p.UUID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for position, yi := range p.Items {
+		if _, err := tx.Exec(`INSERT INTO character_items (character_uuid, instance_id, item_uuid, slot, position, condition)
+			VALUES (?, ?, ?, ?, ?, ?)`, p.UUID, yi.InstanceID, yi.UUID, yi.Slot, position, yi.Condition); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for skill, level := range p.Skills {
+		if _, err := tx.Exec("INSERT INTO character_skills (character_uuid, skill, level) VALUES (?, ?, ?)",
+			p.UUID, skill, level); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for position, q := range p.Quests {
+		for step, s := range q.Steps {
+			if _, err := tx.Exec(`INSERT INTO character_quests (character_uuid, position, quest_uuid, step, current)
+				VALUES (?, ?, ?, ?, ?)`, p.UUID, position, q.UUID, step, s.Current); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("Saved %s to database.", player.Name)
+	return nil
+}
+
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
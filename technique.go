@@ -0,0 +1,158 @@
+package main
+
+import (
+	"github.com/michaelvmata/path/buffs"
+	"github.com/michaelvmata/path/simulate"
+	"github.com/michaelvmata/path/skills"
+	"github.com/michaelvmata/path/world"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Technique is a skill command whose behavior is composed from the effects
+// in its skill file.
+type Technique struct {
+	Name string
+}
+
+func (t Technique) Execute(ctx Context) {
+	attacker := ctx.Player
+	if !CanUseSkill(ctx.World, attacker, t.Name) {
+		return
+	}
+	definition := ctx.World.Skills[t.Name]
+	if definition.Opener && attacker.IsFighting() {
+		attacker.Showln("You can't %s while in combat.", t.Name)
+		return
+	}
+	if definition.Target == skills.Self {
+		UseSkill(ctx.World, attacker, t.Name)
+		DoTechnique(ctx.World, attacker, attacker, t.Name)
+		return
+	}
+
+	defender := FindTarget(attacker, ctx.Raw)
+	if defender == nil {
+		attacker.Showln("%s who?", definition.Name)
+		return
+	}
+
+	InitBattleSkill(ctx.World, attacker, defender, t.Name)
+	DoTechnique(ctx.World, attacker, defender, t.Name)
+}
+
+func (t Technique) Label() string {
+	return t.Name
+}
+
+// DoTechnique runs the technique's effects in order against the defender,
+// or against each opponent for techniques that target them all.  Effects
+// stop once their defender dies.
+func DoTechnique(World *world.World, attacker *world.Character, defender *world.Character, skill string) {
+	definition := World.Skills[skill]
+	level := attacker.Skills.Level(skill)
+	defenders := []*world.Character{defender}
+	if definition.Target == skills.Opponents {
+		defenders = append([]*world.Character{}, attacker.Attacking...)
+	}
+	for _, defender := range defenders {
+		if definition.Expected != nil && defender.Memory.MostRecent() == skill {
+			showTechnique(attacker, defender, definition.Expected, 0)
+			continue
+		}
+		for _, effect := range definition.Effects {
+			if !DoEffect(World, attacker, defender, skill, level, effect) {
+				break
+			}
+		}
+	}
+}
+
+// DoEffect applies one effect and reports whether the defender survived it.
+func DoEffect(World *world.World, attacker *world.Character, defender *world.Character, skill string, level int, effect skills.Effect) bool {
+	if effect.Damage != nil {
+		for i := 0; i < effect.Damage.Times(level); i++ {
+			hit := 0
+			if effect.Damage.Hit.At(level) != 0 {
+				hit = simulate.CalculateHitDamage(World.Random, attacker, defender).Amount
+			}
+			amount := effect.Damage.Amount(level, hit)
+			showTechnique(attacker, defender, effect.Message, amount)
+			if simulate.DoDamage(attacker, defender, amount) {
+				return false
+			}
+		}
+		return true
+	}
+
+	showTechnique(attacker, defender, effect.Message, 0)
+	switch {
+	case effect.Stun > 0:
+		defender.Control(world.Stun, effect.Stun)
+	case effect.Root > 0:
+		defender.Control(world.Root, effect.Root)
+	case effect.Silence > 0:
+		defender.Control(world.Silence, effect.Silence)
+	case effect.Slow > 0:
+		defender.Control(world.Slow, effect.Slow)
+	case effect.Buff != "":
+		defender.Apply(buffs.Constructors[effect.Buff](defender, attacker))
+	case effect.Memory > 0:
+		defender.Memory.AddGameEvent(skill, effect.Memory)
+	case effect.Threat != nil:
+		defender.Threat.Add(attacker, effect.Threat.At(level))
+	}
+	return true
+}
+
+func showTechnique(attacker *world.Character, defender *world.Character, messages *skills.Messages, amount int) {
+	if messages == nil {
+		return
+	}
+	replacer := strings.NewReplacer("$n", attacker.Name, "$N", defender.Name, "$d", strconv.Itoa(amount))
+	message := world.Message{
+		FirstPerson:         attacker,
+		FirstPersonMessage:  replacer.Replace(messages.First),
+		SecondPerson:        defender,
+		SecondPersonMessage: replacer.Replace(messages.Second),
+		ThirdPersonMessage:  replacer.Replace(messages.Third),
+	}
+	if defender == attacker {
+		message.SecondPerson = nil
+	}
+	if err := attacker.Room.ShowMessage(message); err != nil {
+		log.Fatalf("Problem showing technique message: %v", err)
+	}
+}
+
+// checkTechniques makes sure every buff a technique applies exists.
+func checkTechniques(registry skills.Registry) {
+	for _, name := range registry.Names() {
+		for _, effect := range registry[name].Effects {
+			if _, ok := buffs.Constructors[effect.Buff]; effect.Buff != "" && !ok {
+				log.Fatalf("Skill %s applies unknown buff %s", name, effect.Buff)
+			}
+		}
+	}
+}
+
+// addTechniques gives techniques that aren't built in a command, taking
+// only the aliases no other command has claimed.
+func addTechniques(commands map[string]Executor, registry skills.Registry) {
+	for _, name := range registry.Names() {
+		if !registry[name].IsTechnique() {
+			continue
+		}
+		if _, ok := commands[name]; ok {
+			continue
+		}
+		technique := Technique{Name: name}
+		for i := range name {
+			if _, ok := commands[name[:i+1]]; !ok {
+				commands[name[:i+1]] = technique
+			}
+		}
+		log.Printf("Added technique %s", name)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/michaelvmata/path/skills"
+	"github.com/michaelvmata/path/world"
+	"testing"
+)
+
+func TestTechnique(t *testing.T) {
+	w := build("data/areas")
+	w.Seed(world.TestSeed)
+	player := w.Players["gaigen"]
+	target := world.NewPlayer("Target UUID", "Target")
+	target.Room = player.Room
+	player.Room.Enter(target)
+	target.Health.Maximum = 10000
+	target.Health.Current = 10000
+
+	player.Skills.Get("bash").Base = 2
+	DoTechnique(w, player, target, "bash")
+	if damage := 10000 - target.Health.Current; damage != 14 {
+		t.Fatalf("Bash damage expected(14) actual(%d)", damage)
+	}
+	if !target.IsStunned() || target.Threat[player] != 14+20 {
+		t.Fatalf("Bash didn't stun or provoke %d", target.Threat[player])
+	}
+
+	player.Skills.Get("circle").Base = 1
+	before := target.Health.Current
+	DoTechnique(w, player, target, "circle")
+	if target.Health.Current >= before || target.Memory.MostRecent() != "circle" {
+		t.Fatalf("Circle missed")
+	}
+	before = target.Health.Current
+	DoTechnique(w, player, target, "circle")
+	if target.Health.Current != before {
+		t.Fatalf("Expected circle landed")
+	}
+
+	target.Health.Current = 1
+	stunned := target.Controls[world.Stun].Applications
+	player.Skills.Get("blitz").Base = 5
+	DoTechnique(w, player, target, "blitz")
+	if !target.IsDead() || target.Controls[world.Stun].Applications != stunned {
+		t.Fatalf("Blitz kept going after the target died")
+	}
+}
+
+func TestAddTechniques(t *testing.T) {
+	commands := buildCommands()
+	registry := skills.Registry{
+		"kick": skills.Definition{Name: "Kick", Effects: []skills.Effect{{Stun: 1}}},
+		"bolt": skills.Definition{Name: "Bolt", Effects: []skills.Effect{{Stun: 1}}},
+	}
+	addTechniques(commands, registry)
+	if commands["kick"] != (Technique{Name: "kick"}) || commands["k"] != (Technique{Name: "kick"}) {
+		t.Fatalf("Kick not added as a command")
+	}
+	if commands["bolt"] != (Technique{Name: "bolt"}) || commands["b"] != (Technique{Name: "blitz"}) {
+		t.Fatalf("Bolt took an existing alias")
+	}
+}
+
+func TestSelfTechnique(t *testing.T) {
+	w := build("data/areas")
+	player := w.Players["gaigen"]
+	player.Skills.Get("regenerate").Base = 1
+	ctx := Context{World: w, Player: player, Raw: "regenerate"}
+	Technique{Name: "regenerate"}.Execute(ctx)
+	if !player.HasBuff("regenerate") || player.IsFighting() || !player.OnCoolDown("regenerate") {
+		t.Fatalf("Regenerate not applied to self")
+	}
+}
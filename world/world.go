@@ -12,7 +12,11 @@ import (
 	"github.com/michaelvmata/path/stats"
 	"github.com/michaelvmata/path/symbols"
 	"log"
+	"math"
+	"math/rand"
+	"sort"
 	"strings"
+	"time"
 )
 
 type Buff interface {
@@ -25,6 +29,74 @@ type Buff interface {
 	AlreadyApplied() string
 	Upkeep() int
 	Remaining() int
+	Stacking() Stacking
+	Stacks() int
+	Refresh()
+	Intensify()
+	Categories() []Category
+}
+
+// Ticker is a buff with an effect every battle tick, like bleeding or
+// regeneration.
+type Ticker interface {
+	Tick() Tick
+}
+
+// Tick is one battle tick of a periodic effect on the character carrying
+// it.  Source is credited with the damage and healing, Spirit restores
+// spirit or drains it when negative, and Message is shown before any of it.
+// Healed and Drained, when set, describe the health actually restored and
+// the spirit actually drained.  Absorb gives the drained spirit to Source.
+type Tick struct {
+	Source  *Character
+	Damage  int
+	Heal    int
+	Spirit  int
+	Absorb  bool
+	Message Message
+	Healed  func(amount int) Message
+	Drained func(amount int) Message
+}
+
+// Rules for applying a buff that's already present.  Refresh restarts it,
+// Intensify restarts it and adds a stack up to the maximum and Independent
+// buffs are applied separately every time.
+const (
+	Refresh     = "refresh"
+	Intensify   = "intensify"
+	Independent = "independent"
+)
+
+type Stacking struct {
+	Rule    string
+	Maximum int
+}
+
+// Category groups buffs so they can be dispelled or cleansed together.
+type Category string
+
+const (
+	Beneficial Category = "beneficial"
+	Harmful    Category = "harmful"
+	Magic      Category = "magic"
+	Physical   Category = "physical"
+)
+
+// HasCategories is true when the buff is in every category.
+func HasCategories(buff Buff, categories ...Category) bool {
+	for _, category := range categories {
+		found := false
+		for _, c := range buff.Categories() {
+			if c == category {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 type CoolDown interface {
@@ -33,6 +105,47 @@ type CoolDown interface {
 	Name() string
 }
 
+// MaximumResistance caps how much damage of a type can be avoided.
+const MaximumResistance = 75
+
+// Resistances are percentages of damage avoided per damage type.  Negative
+// values are vulnerabilities and add damage.
+type Resistances struct {
+	Crush  stats.Stat
+	Pierce stats.Stat
+	Slash  stats.Stat
+}
+
+// Of returns the resistance to a damage type, capped at MaximumResistance.
+func (r *Resistances) Of(damageType string) int {
+	var resistance int
+	switch strings.ToLower(damageType) {
+	case item.Crush:
+		resistance = r.Crush.Value()
+	case item.Pierce:
+		resistance = r.Pierce.Value()
+	case item.Slash:
+		resistance = r.Slash.Value()
+	}
+	if resistance > MaximumResistance {
+		resistance = MaximumResistance
+	}
+	return resistance
+}
+
+func (r *Resistances) ResetModifier() {
+	r.Crush.Reset()
+	r.Pierce.Reset()
+	r.Slash.Reset()
+}
+
+func (r *Resistances) Describe() string {
+	return fmt.Sprintf("%s Crush %d%%  %s Pierce %d%%  %s Slash %d%%",
+		symbols.HEAVY_GREEK_CROSS, r.Of(item.Crush),
+		symbols.HEAVY_GREEK_CROSS, r.Of(item.Pierce),
+		symbols.HEAVY_GREEK_CROSS, r.Of(item.Slash))
+}
+
 type Character struct {
 	UUID    string
 	Name    string
@@ -46,8 +159,9 @@ type Character struct {
 	Health stats.Line
 	Spirit stats.Line
 
-	Core   stats.Core
-	Skills skills.Skills
+	Core        stats.Core
+	Skills      skills.Skills
+	Resistances Resistances
 
 	Gear      *item.Gear
 	Inventory item.Container
@@ -55,6 +169,10 @@ type Character struct {
 	Essence int
 
 	Attacking []*Character
+	Threat    Threat
+
+	Following *Character
+	Group     *Group
 
 	Buffs     []Buff
 	CoolDowns []CoolDown
@@ -65,7 +183,7 @@ type Character struct {
 	IsSocial     bool
 	IsPlayer     bool
 
-	Stunned int
+	Controls Controls
 }
 
 func (c *Character) CreditEssence(amount int) {
@@ -76,9 +194,20 @@ func (c *Character) DebitEssence(amount int) {
 	c.Essence -= amount
 }
 
+// Apply adds the buff, or stacks it onto the one already applied by its
+// stacking rule.
 func (c *Character) Apply(buff Buff) {
-	for _, b := range c.Buffs {
-		if b.Name() == buff.Name() {
+	stacking := buff.Stacking()
+	if stacking.Rule != Independent {
+		for _, b := range c.Buffs {
+			if b.Name() != buff.Name() {
+				continue
+			}
+			if stacking.Rule == Intensify && b.Stacks() < stacking.Maximum {
+				b.Intensify()
+			} else {
+				b.Refresh()
+			}
 			c.Showln(buff.AlreadyApplied())
 			return
 		}
@@ -87,28 +216,48 @@ func (c *Character) Apply(buff Buff) {
 	c.Showln(buff.ApplyMessage())
 }
 
-func (c *Character) Unapply(buffName string) {
-	remaining := make([]Buff, 0)
-	for _, buff := range c.Buffs {
-		if buff.Name() != buffName {
-			remaining = append(remaining, buff)
-		} else {
+// Unapply removes the buff itself, leaving independent copies with the same
+// name applied.
+func (c *Character) Unapply(buff Buff) error {
+	for i, b := range c.Buffs {
+		if b == buff {
+			c.Buffs = append(c.Buffs[:i:i], c.Buffs[i+1:]...)
 			c.Showln(buff.UnapplyMessage())
+			return nil
 		}
 	}
-	if len(remaining) == len(c.Buffs) {
-		log.Fatalf("Tried to unapply buff that wasn't applied %s", buffName)
+	return errors.New("buff not applied")
+}
+
+// Dispel removes up to limit buffs in all the categories, oldest first, and
+// returns them.
+func (c *Character) Dispel(limit int, categories ...Category) []Buff {
+	remaining := make([]Buff, 0, len(c.Buffs))
+	removed := make([]Buff, 0)
+	for _, buff := range c.Buffs {
+		if len(removed) < limit && HasCategories(buff, categories...) {
+			removed = append(removed, buff)
+			c.Showln(buff.UnapplyMessage())
+		} else {
+			remaining = append(remaining, buff)
+		}
 	}
 	c.Buffs = remaining
+	return removed
 }
 
 func (c *Character) HasBuff(buffName string) bool {
+	return c.GetBuff(buffName) != nil
+}
+
+// GetBuff returns the oldest buff applied with the name, or nil.
+func (c *Character) GetBuff(buffName string) Buff {
 	for _, buff := range c.Buffs {
 		if buff.Name() == buffName {
-			return true
+			return buff
 		}
 	}
-	return false
+	return nil
 }
 
 func (c *Character) UnapplyExpiredBuffs() {
@@ -163,35 +312,42 @@ func (c *Character) OnCoolDown(name string) bool {
 	return false
 }
 
-func (c *Character) Stun(length int) {
-	c.Stunned += length
-}
-
-func (c *Character) ReduceStun() {
-	if c.Stunned > 0 {
-		c.Stunned--
+// Control applies crowd control and returns how many rounds it lasts, 0 when
+// the character resists it.
+func (c *Character) Control(control Control, duration int) int {
+	applied := c.Controls.Apply(control, duration)
+	if applied > 0 {
+		c.Showln("You are %s!", controlled[control])
+	} else {
+		c.Showln("You resist being %s.", controlled[control])
 	}
+	return applied
 }
 
 func (c *Character) Aggro() {
 	if c.IsFighting() || c.IsPlayer || !c.IsAggressive {
 		return
 	}
+	candidates := make([]*Character, 0)
 	for _, candidate := range c.Room.Players {
-		if !candidate.IsPlayer {
-			continue
+		if candidate.IsPlayer {
+			candidates = append(candidates, candidate)
 		}
-		c.StartAttacking(candidate)
-		candidate.StartAttacking(c)
+	}
+	// Grudges from earlier fights decide who is attacked first
+	target := c.Threat.Highest(candidates)
+	if target == nil {
+		return
+	}
+	c.StartAttacking(target)
+	target.StartAttacking(c)
 
-		message := Message{
-			FirstPerson:        c,
-			FirstPersonMessage: "You scream, \"This is SPARTA!\"",
-			ThirdPersonMessage: fmt.Sprintf("%s screams, \"This is SPARTA!\"", c.Name),
-		}
-		c.Room.ShowMessage(message)
-		break
+	message := Message{
+		FirstPerson:        c,
+		FirstPersonMessage: "You scream, \"This is SPARTA!\"",
+		ThirdPersonMessage: fmt.Sprintf("%s screams, \"This is SPARTA!\"", c.Name),
 	}
+	c.Room.ShowMessage(message)
 }
 
 func (c *Character) Social() {
@@ -221,8 +377,63 @@ func (c *Character) Social() {
 	}
 }
 
+// Assist joins an ally's fight against whoever they are attacking, and
+// returns whether there was a fight to join.
+func (c *Character) Assist(ally *Character) bool {
+	target := ally.ImmediateDefender()
+	if target == nil || target == c {
+		return false
+	}
+	c.StartAttacking(target)
+	target.StartAttacking(c)
+
+	message := Message{
+		FirstPerson:         c,
+		FirstPersonMessage:  fmt.Sprintf("You rush to assist %s.", ally.Name),
+		SecondPerson:        ally,
+		SecondPersonMessage: fmt.Sprintf("%s rushes to assist you.", c.Name),
+		ThirdPersonMessage:  fmt.Sprintf("%s rushes to assist %s.", c.Name, ally.Name),
+	}
+	c.Room.ShowMessage(message)
+	return true
+}
+
+// AutoAssist has an idle character join the fight of a group member in the
+// same room.
+func (c *Character) AutoAssist() {
+	if c.IsFighting() || c.Group == nil {
+		return
+	}
+	for _, member := range c.GroupInRoom() {
+		if member != c && c.Assist(member) {
+			break
+		}
+	}
+}
+
+// GroupInRoom lists the character's group members in the same room, the
+// character included.  Ungrouped characters are on their own.
+func (c *Character) GroupInRoom() []*Character {
+	if c.Group == nil {
+		return []*Character{c}
+	}
+	return c.Group.InRoom(c.Room)
+}
+
 func (c *Character) IsStunned() bool {
-	return c.Stunned > 0
+	return c.Controls.Has(Stun)
+}
+
+func (c *Character) IsRooted() bool {
+	return c.Controls.Has(Root)
+}
+
+func (c *Character) IsSilenced() bool {
+	return c.Controls.Has(Silence)
+}
+
+func (c *Character) IsSlowed() bool {
+	return c.Controls.Has(Slow)
 }
 
 func (c *Character) Weapon() *item.Weapon {
@@ -250,12 +461,18 @@ func (c *Character) Clone(target Character) {
 	c.Core.Insight.Base = target.Core.Insight.Base
 	c.Core.Will.Base = target.Core.Will.Base
 
+	c.Resistances.Crush.Base = target.Resistances.Crush.Base
+	c.Resistances.Pierce.Base = target.Resistances.Pierce.Base
+	c.Resistances.Slash.Base = target.Resistances.Slash.Base
+
 	c.IsAggressive = target.IsAggressive
 	c.IsSocial = target.IsSocial
 
 	c.Gear = item.NewGear()
 	c.Inventory = item.NewContainer(10)
 	c.Attacking = make([]*Character, 0)
+	c.Threat = make(Threat)
+	c.Controls = make(Controls)
 }
 
 func NewPlayer(UUID string, handle string) *Character {
@@ -275,6 +492,8 @@ func NewPlayer(UUID string, handle string) *Character {
 		Inventory: item.NewContainer(10),
 
 		Attacking: make([]*Character, 0),
+		Threat:    make(Threat),
+		Controls:  make(Controls),
 
 		Memory:       memory.NewMemory(),
 		Quests:       make([]*quest.Quest, 0),
@@ -326,6 +545,152 @@ func (c *Character) StartAttacking(defender *Character) {
 	c.Attacking = append(c.Attacking, defender)
 }
 
+// Threat is how much each opponent has provoked a character through damage,
+// skills and healing.  Mobiles attack whoever provoked them most.
+type Threat map[*Character]int
+
+func (t Threat) Add(c *Character, amount int) {
+	if c == nil || amount <= 0 {
+		return
+	}
+	t[c] += amount
+}
+
+// Taunt puts the character ahead of everyone else in the table.
+func (t Threat) Taunt(c *Character) {
+	highest := 0
+	for _, amount := range t {
+		if amount > highest {
+			highest = amount
+		}
+	}
+	t[c] = highest + 1
+}
+
+func (t Threat) Remove(c *Character) {
+	delete(t, c)
+}
+
+// Highest returns the candidate with the most threat.  Ties go to the
+// earlier candidate.
+func (t Threat) Highest(candidates []*Character) *Character {
+	var target *Character
+	for _, candidate := range candidates {
+		if target == nil || t[candidate] > t[target] {
+			target = candidate
+		}
+	}
+	return target
+}
+
+// Control is a kind of crowd control.  Stunned characters can't act or
+// defend themselves, rooted characters can't move, silenced characters
+// can't use skills and slowed characters attack less and act later.
+// Controls last a number of battle rounds.
+type Control string
+
+const (
+	Stun    Control = "stun"
+	Root    Control = "root"
+	Silence Control = "silence"
+	Slow    Control = "slow"
+)
+
+var controlled = map[Control]string{
+	Stun:    "stunned",
+	Root:    "rooted",
+	Silence: "silenced",
+	Slow:    "slowed",
+}
+
+// DiminishingReturns scales each application of a control within the
+// DiminishingWindow.  Once they run out the character resists the control
+// until the window passes.
+var DiminishingReturns = []float64{1, 0.5, 0.25}
+
+// DiminishingWindow is how many battle rounds after the last application a
+// control keeps diminishing.
+const DiminishingWindow = 5
+
+// Controlled tracks one control on a character, the rounds it has left and
+// how often it was applied within the window.
+type Controlled struct {
+	Remaining    int
+	Applications int
+	Window       int
+}
+
+type Controls map[Control]*Controlled
+
+// Apply returns the diminished duration, which never lengthens a control
+// already in place.
+func (cs Controls) Apply(control Control, duration int) int {
+	c, ok := cs[control]
+	if !ok {
+		c = &Controlled{}
+		cs[control] = c
+	}
+	if duration <= 0 || c.Applications >= len(DiminishingReturns) {
+		return 0
+	}
+	duration = int(math.Ceil(float64(duration) * DiminishingReturns[c.Applications]))
+	c.Applications++
+	c.Window = DiminishingWindow
+	if duration > c.Remaining {
+		c.Remaining = duration
+	}
+	return duration
+}
+
+func (cs Controls) Has(control Control) bool {
+	c, ok := cs[control]
+	return ok && c.Remaining > 0
+}
+
+// Update counts down a battle round.
+func (cs Controls) Update() {
+	for control, c := range cs {
+		if c.Remaining > 0 {
+			c.Remaining--
+		}
+		if c.Window > 0 {
+			c.Window--
+		}
+		if c.Remaining == 0 && c.Window == 0 {
+			delete(cs, control)
+		}
+	}
+}
+
+// Describe lists the controls in effect with their remaining rounds.
+func (cs Controls) Describe() []string {
+	lines := make([]string, 0)
+	for _, control := range []Control{Stun, Root, Silence, Slow} {
+		if cs.Has(control) {
+			lines = append(lines, fmt.Sprintf("Name: %s\tRounds: %d", control, cs[control].Remaining))
+		}
+	}
+	return lines
+}
+
+// ChooseTarget has a mobile turn on the opponent with the most threat.
+// Players choose their own targets.
+func (c *Character) ChooseTarget() {
+	if c.IsPlayer {
+		return
+	}
+	if target := c.Threat.Highest(c.Attacking); target != nil && target != c.ImmediateDefender() {
+		c.AttackFirst(target)
+	}
+}
+
+// AttackFirst puts the defender at the front of Attacking so they take the
+// character's next attacks.
+func (c *Character) AttackFirst(defender *Character) {
+	c.StopAttacking(defender)
+	c.Attacking = append([]*Character{defender}, c.Attacking...)
+}
+
 func (c *Character) StopAttacking(defender *Character) {
 	index := -1
 	for i, target := range c.Attacking {
@@ -362,6 +727,27 @@ func (c *Character) Discard(keyword string) item.Item {
 	return i
 }
 
+// TakeAmmunition removes the first ammunition carried, or returns nil when
+// there is none.
+func (c *Character) TakeAmmunition() item.Item {
+	for index, i := range c.Inventory.Items {
+		if i.Type() == item.AmmunitionType {
+			return c.Inventory.RemItemAtIndex(index)
+		}
+	}
+	return nil
+}
+
+// HasItem is true when the character carries an item with the UUID.
+func (c *Character) HasItem(UUID string) bool {
+	for _, i := range c.Inventory.Items {
+		if i.UUID() == UUID {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Character) Receive(i item.Item) error {
 	if err := c.Inventory.AddItem(i); err != nil {
 		return errors.New("player can't carry item")
@@ -384,6 +770,18 @@ func (c *Character) ApplyModifiers(mods []modifiers.Modifier) {
 			c.Core.Insight.Modify(mod.Value)
 		case modifiers.Will:
 			c.Core.Will.Modify(mod.Value)
+		case modifiers.CrushResistance:
+			c.Resistances.Crush.Modify(mod.Value)
+		case modifiers.PierceResistance:
+			c.Resistances.Pierce.Modify(mod.Value)
+		case modifiers.SlashResistance:
+			c.Resistances.Slash.Modify(mod.Value)
+		case modifiers.CrushVulnerability:
+			c.Resistances.Crush.Modify(-mod.Value)
+		case modifiers.PierceVulnerability:
+			c.Resistances.Pierce.Modify(-mod.Value)
+		case modifiers.SlashVulnerability:
+			c.Resistances.Slash.Modify(-mod.Value)
 		}
 	}
 }
@@ -396,18 +794,10 @@ func (c *Character) ApplyItemModifiers(i item.Item) {
 
 func (c *Character) CalculateModifiers() {
 	c.Core.ResetModifier()
-	c.ApplyItemModifiers(c.Gear.Head)
-	c.ApplyItemModifiers(c.Gear.Neck)
-	c.ApplyItemModifiers(c.Gear.Body)
-	c.ApplyItemModifiers(c.Gear.Arms)
-	c.ApplyItemModifiers(c.Gear.Hands)
-	c.ApplyItemModifiers(c.Gear.Waist)
-	c.ApplyItemModifiers(c.Gear.Legs)
-	c.ApplyItemModifiers(c.Gear.Feet)
-	c.ApplyItemModifiers(c.Gear.Wrist)
-	c.ApplyItemModifiers(c.Gear.Fingers)
-	c.ApplyItemModifiers(c.Gear.OffHand)
-	c.ApplyItemModifiers(c.Gear.MainHand)
+	c.Resistances.ResetModifier()
+	for _, i := range c.Gear.Items() {
+		c.ApplyItemModifiers(i)
+	}
 }
 
 func (c *Character) IsDead() bool {
@@ -415,6 +805,7 @@ func (c *Character) IsDead() bool {
 }
 
 func (c *Character) Restore() {
+	c.Controls = make(Controls)
 	c.CalculateModifiers()
 	// Adjust lines from core stats
 
@@ -451,9 +842,9 @@ func (c *Character) Update(tick int) {
 			cd.Update(tick)
 		}
 		c.UnapplyExpiredCoolDowns()
-		c.ReduceStun()
 		c.Aggro()
 		c.Social()
+		c.AutoAssist()
 
 		c.Memory.Update(tick)
 	}
@@ -473,14 +864,14 @@ func (c *Character) UpdateQuests() {
 
 func (c *Character) ShowNewline() {
 	if c.Session != nil {
-		c.Session.Outgoing <- "\n"
+		c.Session.Write("\n")
 	}
 }
 
 func (c *Character) Showln(message string, args ...interface{}) {
 	if c.Session != nil {
-		c.Session.Outgoing <- fmt.Sprintf(message, args...)
-		c.Session.Outgoing <- "\n"
+		c.Session.Write(fmt.Sprintf(message, args...))
+		c.Session.Write("\n")
 	}
 }
 
@@ -490,13 +881,13 @@ func (c *Character) AdjustEssence(amount int) {
 
 func (c *Character) ShowDivider() {
 	if c.Session != nil {
-		c.Session.Outgoing <- "------------------------------------------------------------"
+		c.Session.Write(strings.Repeat("-", c.Session.Width()))
 	}
 }
 
 func (c *Character) Show(message string, args ...interface{}) {
 	if c.Session != nil {
-		c.Session.Outgoing <- fmt.Sprintf(message, args...)
+		c.Session.Write(fmt.Sprintf(message, args...))
 	}
 }
 
@@ -515,6 +906,81 @@ func (c *Character) LongDescribe() string {
 	return strings.Join(parts, "\n")
 }
 
+var (
+	AlreadyGrouped = errors.New("already in a group")
+	NotGrouped     = errors.New("not in the group")
+)
+
+// Group is a party of characters who fight together and share the essence
+// and quest credit of their kills.
+type Group struct {
+	Leader  *Character
+	Members []*Character
+}
+
+func NewGroup(leader *Character) *Group {
+	g := &Group{Leader: leader, Members: []*Character{leader}}
+	leader.Group = g
+	return g
+}
+
+func (g *Group) Add(c *Character) error {
+	if c.Group != nil {
+		return AlreadyGrouped
+	}
+	g.Members = append(g.Members, c)
+	c.Group = g
+	return nil
+}
+
+// Remove takes a member out of the group.  The group disbands when the
+// leader leaves or nobody would be left to lead.
+func (g *Group) Remove(c *Character) error {
+	if c.Group != g {
+		return NotGrouped
+	}
+	if c == g.Leader || len(g.Members) <= 2 {
+		g.Disband()
+		return nil
+	}
+	for i, member := range g.Members {
+		if member == c {
+			g.Members = append(g.Members[:i], g.Members[i+1:]...)
+			break
+		}
+	}
+	c.Group = nil
+	return nil
+}
+
+func (g *Group) Disband() {
+	for _, member := range g.Members {
+		member.Group = nil
+	}
+	g.Members = nil
+}
+
+// Find returns the member with the keyword.
+func (g *Group) Find(keyword string) *Character {
+	for _, member := range g.Members {
+		if member.HasKeyword(keyword) {
+			return member
+		}
+	}
+	return nil
+}
+
+// InRoom lists the members in the room.
+func (g *Group) InRoom(room *Room) []*Character {
+	members := make([]*Character, 0, len(g.Members))
+	for _, member := range g.Members {
+		if member.Room == room {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
 type RoomMobile struct {
 	MobileUUID string
 	Count      int
@@ -527,29 +993,188 @@ func NewRoomMobile(mobileUUID string, count int) RoomMobile {
 	}
 }
 
-type Exits struct {
-	East  string
-	North string
-	South string
-	West  string
+type RoomItem struct {
+	ItemUUID string
+	Count    int
 }
 
-func (e Exits) FirstExit() string {
-	if e.East != "" {
-		return e.East
+func NewRoomItem(itemUUID string, count int) RoomItem {
+	return RoomItem{
+		ItemUUID: itemUUID,
+		Count:    count,
+	}
+}
+
+const (
+	North     = "north"
+	East      = "east"
+	South     = "south"
+	West      = "west"
+	Northeast = "northeast"
+	Northwest = "northwest"
+	Southeast = "southeast"
+	Southwest = "southwest"
+	Up        = "up"
+	Down      = "down"
+)
+
+// Directions are the standard exit names in the order they are listed.
+// Exits with any other name are listed after them alphabetically.
+var Directions = []string{North, East, South, West, Northeast, Northwest, Southeast, Southwest, Up, Down}
+
+var (
+	DoorClosed    = errors.New("door is closed")
+	DoorOpen      = errors.New("door is open")
+	DoorLocked    = errors.New("door is locked")
+	DoorUnlocked  = errors.New("door is unlocked")
+	DoorHasNoLock = errors.New("door has no lock")
+)
+
+// Door blocks an exit while closed.  Both sides of a passage share the
+// same Door so opening it from one room opens it in the other.
+type Door struct {
+	Name    string
+	Closed  bool
+	Locked  bool
+	KeyUUID string
+}
+
+func (d *Door) Open() error {
+	if d.Locked {
+		return DoorLocked
+	}
+	if !d.Closed {
+		return DoorOpen
+	}
+	d.Closed = false
+	return nil
+}
+
+func (d *Door) Close() error {
+	if d.Closed {
+		return DoorClosed
+	}
+	d.Closed = true
+	return nil
+}
+
+func (d *Door) Lock() error {
+	if d.KeyUUID == "" {
+		return DoorHasNoLock
+	}
+	if d.Locked {
+		return DoorLocked
+	}
+	if !d.Closed {
+		return DoorOpen
+	}
+	d.Locked = true
+	return nil
+}
+
+func (d *Door) Unlock() error {
+	if d.KeyUUID == "" {
+		return DoorHasNoLock
+	}
+	if !d.Locked {
+		return DoorUnlocked
+	}
+	d.Locked = false
+	return nil
+}
+
+type Exit struct {
+	RoomUUID string
+	Door     *Door
+}
+
+// IsPassable is true when there is no door or the door is open.
+func (e *Exit) IsPassable() bool {
+	return e.Door == nil || !e.Door.Closed
+}
+
+// Exits maps an exit name, like "north" or "portal", to its exit.
+type Exits map[string]*Exit
+
+// Names lists the exits, standard directions first.
+func (e Exits) Names() []string {
+	names := make([]string, 0, len(e))
+	for _, direction := range Directions {
+		if _, ok := e[direction]; ok {
+			names = append(names, direction)
+		}
+	}
+	custom := make([]string, 0)
+	for name := range e {
+		if !IsDirection(name) {
+			custom = append(custom, name)
+		}
 	}
-	if e.North != "" {
-		return e.North
+	sort.Strings(custom)
+	return append(names, custom...)
+}
+
+// Find returns the exit with the given name, or the first exit whose name
+// starts with it.
+func (e Exits) Find(name string) (string, *Exit, bool) {
+	if exit, ok := e[name]; ok {
+		return name, exit, true
 	}
-	if e.South != "" {
-		return e.South
+	if name == "" {
+		return "", nil, false
 	}
-	if e.West != "" {
-		return e.West
+	for _, candidate := range e.Names() {
+		if strings.HasPrefix(candidate, name) {
+			return candidate, e[candidate], true
+		}
+	}
+	return "", nil, false
+}
+
+// FindDoor returns the exit with a door matching an exit or door name.
+func (e Exits) FindDoor(keyword string) (string, *Exit, bool) {
+	if name, exit, ok := e.Find(keyword); ok && exit.Door != nil {
+		return name, exit, true
+	}
+	for _, name := range e.Names() {
+		exit := e[name]
+		if exit.Door != nil && exit.Door.Name == keyword {
+			return name, exit, true
+		}
+	}
+	return "", nil, false
+}
+
+// FirstExit returns the destination of the first passable exit.
+func (e Exits) FirstExit() string {
+	for _, name := range e.Names() {
+		if e[name].IsPassable() {
+			return e[name].RoomUUID
+		}
 	}
 	return ""
 }
 
+// Toward returns the name of the first exit leading to the room.
+func (e Exits) Toward(roomUUID string) (string, bool) {
+	for _, name := range e.Names() {
+		if e[name].RoomUUID == roomUUID {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// IsDirection is true for the standard exit names.
+func IsDirection(name string) bool {
+	for _, direction := range Directions {
+		if direction == name {
+			return true
+		}
+	}
+	return false
+}
+
 type Room struct {
 	UUID        string
 	name        string
@@ -569,7 +1194,7 @@ func NewRoom(uuid string, name string, description string, size int, area *Area)
 		Size:        size,
 		Players:     make([]*Character, 0, size),
 		Items:       item.NewContainer(100),
-		Exits:       Exits{},
+		Exits:       make(Exits),
 		Area:        area,
 	}
 	return &room
@@ -627,17 +1252,16 @@ func (r *Room) Describe(firstPerson *Character) string {
 
 func (r *Room) DescribeExits() string {
 	parts := make([]string, 0)
-	if r.Exits.East != "" {
-		parts = append(parts, "east")
-	}
-	if r.Exits.North != "" {
-		parts = append(parts, "north")
-	}
-	if r.Exits.South != "" {
-		parts = append(parts, "south")
-	}
-	if r.Exits.West != "" {
-		parts = append(parts, "west")
+	for _, name := range r.Exits.Names() {
+		door := r.Exits[name].Door
+		switch {
+		case door == nil || !door.Closed:
+			parts = append(parts, name)
+		case door.Locked:
+			parts = append(parts, fmt.Sprintf("%s (locked %s)", name, door.Name))
+		default:
+			parts = append(parts, fmt.Sprintf("%s (closed %s)", name, door.Name))
+		}
 	}
 	if len(parts) == 0 {
 		parts = append(parts, "None")
@@ -689,9 +1313,11 @@ func (r *Room) IsFull() bool {
 	return r.Size == len(r.Players)
 }
 
+var RoomFull = errors.New("room is full")
+
 func (r *Room) Enter(c *Character) error {
 	if r.IsFull() {
-		return errors.New("room is full")
+		return RoomFull
 	}
 	if r.IndexOfPlayer(c) != -1 {
 		return errors.New("player already in room")
@@ -746,6 +1372,16 @@ func (r *Room) MobileCount(mobileUUID string) int {
 	return count
 }
 
+func (r *Room) ItemCount(itemUUID string) int {
+	count := 0
+	for _, i := range r.Items.Items {
+		if i.UUID() == itemUUID {
+			count += 1
+		}
+	}
+	return count
+}
+
 func (r *Room) Name() string {
 	return r.name
 }
@@ -754,6 +1390,33 @@ type Area struct {
 	UUID  string
 	Name  string
 	Rooms map[string]*Room
+
+	// ResetTicks is how often the area's mobiles and items are
+	// repopulated.  Zero uses the world's SpawnTicks.
+	ResetTicks int
+
+	// ResetWhenEmpty holds back a due reset until no players are in the
+	// area.
+	ResetWhenEmpty bool
+
+	// ResetMessage is shown to players in the area when it resets.
+	ResetMessage string
+
+	// LastReset is the world tick the area last reset on.
+	LastReset int
+}
+
+// HasPlayers is true when a connected player is in any of the area's rooms.
+// Players who logged out stay in their room but don't hold back a reset.
+func (a *Area) HasPlayers() bool {
+	for _, room := range a.Rooms {
+		for _, c := range room.Players {
+			if c.IsPlayer && c.Session != nil {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func NewArea(UUID string, name string) *Area {
@@ -806,18 +1469,59 @@ func (m *Mobiles) IsInstance(c *Character) bool {
 	return false
 }
 
+// Items holds the item prototypes loaded from areas and every instance
+// spawned from them, keyed by instance ID.
+type Items struct {
+	Prototypes map[string]item.Item
+	Instances  map[string]item.Item
+}
+
+func (i *Items) AddPrototype(p item.Item) {
+	i.Prototypes[p.UUID()] = p
+}
+
+// Spawn creates a new instance of the prototype with the given UUID.
+func (i *Items) Spawn(UUID string) (item.Item, bool) {
+	return i.Restore(UUID, item.NewInstanceID())
+}
+
+// Restore recreates a saved instance of the prototype with the given UUID.
+func (i *Items) Restore(UUID string, instanceID string) (item.Item, bool) {
+	prototype, ok := i.Prototypes[UUID]
+	if !ok {
+		return nil, false
+	}
+	instance := prototype.Instance(instanceID)
+	i.Instances[instanceID] = instance
+	return instance, true
+}
+
+func (i *Items) Unspawn(instance item.Item) {
+	delete(i.Instances, instance.InstanceID())
+}
+
 type World struct {
 	Players     map[string]*Character
 	Mobiles     Mobiles
 	Rooms       map[string]*Room
 	RoomMobiles map[string][]RoomMobile
-	Items       map[string]item.Item
+	RoomItems   map[string][]RoomItem
+	Items       Items
 	Areas       map[string]*Area
 	Quests      map[string]*quest.Quest
+	Skills      skills.Registry
+
+	// Saver persists a player.  World.Update calls it for every player on
+	// each save tick.
+	Saver func(*Character)
+
+	// Random decides every chance in combat.  Seed it to replay fights.
+	Random *rand.Rand
 
 	Ticks       int
 	SpawnTicks  int
 	BattleTicks int
+	SaveTicks   int
 }
 
 func NewWorld() *World {
@@ -829,15 +1533,30 @@ func NewWorld() *World {
 		},
 		Rooms:       make(map[string]*Room),
 		RoomMobiles: make(map[string][]RoomMobile, 0),
-		Items:       make(map[string]item.Item),
+		RoomItems:   make(map[string][]RoomItem, 0),
+		Items: Items{
+			Prototypes: make(map[string]item.Item),
+			Instances:  make(map[string]item.Item),
+		},
 		Areas:       make(map[string]*Area, 0),
 		Quests:      make(map[string]*quest.Quest, 0),
+		Skills:      make(skills.Registry),
 		SpawnTicks:  60,
 		BattleTicks: 3,
+		SaveTicks:   300,
+		Random:      rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 	return &w
 }
 
+// TestSeed is the fixed seed tests use so fights are reproducible.
+const TestSeed = 1
+
+// Seed replaces the random source with one seeded from the given value.
+func (w *World) Seed(seed int64) {
+	w.Random = rand.New(rand.NewSource(seed))
+}
+
 func (w *World) IsMobile(c *Character) bool {
 	return w.Mobiles.IsInstance(c)
 }
@@ -850,45 +1569,188 @@ func (w *World) Update() {
 	for _, mobile := range w.Mobiles.Instances {
 		mobile.Update(w.Ticks)
 	}
-	if w.IsSpawnTick() {
-		w.SpawnMobiles()
+	for _, area := range w.Areas {
+		if w.IsResetDue(area) {
+			w.ResetArea(area)
+		}
+	}
+	if w.IsSaveTick() {
+		w.SavePlayers()
+	}
+}
+
+func (w *World) IsSaveTick() bool {
+	return w.Ticks%w.SaveTicks == 0
+}
+
+func (w *World) SavePlayers() {
+	if w.Saver == nil {
+		return
+	}
+	for _, player := range w.Players {
+		w.Saver(player)
 	}
 }
 
-func (w *World) IsSpawnTick() bool {
-	return w.Ticks%w.SpawnTicks == 0
+// IsResetDue is true once the area's reset interval has passed since its
+// last reset, unless it waits for players to leave.
+func (w *World) IsResetDue(area *Area) bool {
+	ticks := area.ResetTicks
+	if ticks <= 0 {
+		ticks = w.SpawnTicks
+	}
+	if w.Ticks-area.LastReset < ticks {
+		return false
+	}
+	return !area.ResetWhenEmpty || !area.HasPlayers()
 }
 
 func (w *World) IsBattleTick() bool {
 	return w.Ticks%w.BattleTicks == 0
 }
 
-func (w *World) GetItem(uuid string) (item.Item, bool) {
-	item, ok := w.Items[uuid]
-	return item, ok
+func (w *World) SpawnItem(UUID string) (item.Item, bool) {
+	return w.Items.Spawn(UUID)
+}
+
+// SpawnMobile creates a mobile from its prototype, giving it its own
+// instances of the prototype's gear and inventory.
+func (w *World) SpawnMobile(UUID string) *Character {
+	mobile := w.Mobiles.Spawn(UUID)
+	prototype := w.Mobiles.Prototypes[UUID]
+	if prototype.Gear != nil {
+		for _, slot := range item.Slots {
+			p := prototype.Gear.At(slot)
+			if p == nil {
+				continue
+			}
+			if i, ok := w.Items.Spawn(p.UUID()); ok {
+				mobile.Gear.EquipIn(i, slot)
+			}
+		}
+	}
+	for _, p := range prototype.Inventory.Items {
+		if i, ok := w.Items.Spawn(p.UUID()); ok {
+			mobile.Inventory.AddItem(i)
+		}
+	}
+	return mobile
+}
+
+// UnspawnMobile removes a mobile along with the items it carries.
+func (w *World) UnspawnMobile(c *Character) error {
+	if err := w.Mobiles.Unspawn(c); err != nil {
+		return err
+	}
+	for _, i := range c.Gear.Items() {
+		w.Items.Unspawn(i)
+	}
+	for _, i := range c.Inventory.Items {
+		w.Items.Unspawn(i)
+	}
+	return nil
+}
+
+// ResetArea repopulates every room in the area with its mobiles and items.
+func (w *World) ResetArea(area *Area) {
+	area.LastReset = w.Ticks
+	for _, room := range area.Rooms {
+		w.spawnRoomMobiles(room)
+		w.spawnRoomItems(room)
+	}
+	if area.ResetMessage == "" {
+		return
+	}
+	for _, room := range area.Rooms {
+		for _, c := range room.Players {
+			if c.IsPlayer {
+				c.Showln("%s", area.ResetMessage)
+			}
+		}
+	}
 }
 
 func (w *World) SpawnMobiles() {
-	for roomUUID, rms := range w.RoomMobiles {
-		room, ok := w.Rooms[roomUUID]
-		if !ok {
-			continue
+	for roomUUID := range w.RoomMobiles {
+		if room, ok := w.Rooms[roomUUID]; ok {
+			w.spawnRoomMobiles(room)
 		}
-		for _, rm := range rms {
-			count := room.MobileCount(rm.MobileUUID)
-			for diff := rm.Count - count; diff > 0; diff-- {
-				mobile := w.Mobiles.Spawn(rm.MobileUUID)
-				err := room.Enter(mobile)
-				if err != nil {
-					log.Fatalf("Cannot spawn enter mobile %s in room %s", mobile.UUID, room.UUID)
-				}
-				mobile.Room = room
-				mobile.Restore()
+	}
+}
+
+func (w *World) SpawnItems() {
+	for roomUUID := range w.RoomItems {
+		if room, ok := w.Rooms[roomUUID]; ok {
+			w.spawnRoomItems(room)
+		}
+	}
+}
+
+func (w *World) spawnRoomMobiles(room *Room) {
+	for _, rm := range w.RoomMobiles[room.UUID] {
+		count := room.MobileCount(rm.MobileUUID)
+		for diff := rm.Count - count; diff > 0; diff-- {
+			mobile := w.SpawnMobile(rm.MobileUUID)
+			err := room.Enter(mobile)
+			if err != nil {
+				log.Fatalf("Cannot spawn enter mobile %s in room %s", mobile.UUID, room.UUID)
+			}
+			mobile.Room = room
+			mobile.Restore()
+		}
+	}
+}
+
+func (w *World) spawnRoomItems(room *Room) {
+	for _, ri := range w.RoomItems[room.UUID] {
+		count := room.ItemCount(ri.ItemUUID)
+		for diff := ri.Count - count; diff > 0; diff-- {
+			i, ok := w.Items.Spawn(ri.ItemUUID)
+			if !ok {
+				log.Fatalf("Cannot spawn missing item %s in room %s", ri.ItemUUID, room.UUID)
+			}
+			if err := room.Accept(i); err != nil {
+				w.Items.Unspawn(i)
+				break
 			}
 		}
 	}
 }
 
+var (
+	NoExit      = errors.New("no exit")
+	UnknownRoom = errors.New("exit leads to an unknown room")
+	Rooted      = errors.New("character is rooted")
+)
+
+// MoveCharacter moves a character through the named exit, or the first exit
+// starting with the name, and returns the exit's full name.
+func (w *World) MoveCharacter(c *Character, name string) (string, error) {
+	if c.IsRooted() {
+		return name, Rooted
+	}
+	name, exit, ok := c.Room.Exits.Find(name)
+	if !ok {
+		return "", NoExit
+	}
+	if !exit.IsPassable() {
+		return name, DoorClosed
+	}
+	room, ok := w.Rooms[exit.RoomUUID]
+	if !ok {
+		return name, UnknownRoom
+	}
+	if err := room.Enter(c); err != nil {
+		return name, err
+	}
+	if err := c.Room.Exit(c); err != nil {
+		room.Exit(c)
+		return name, err
+	}
+	c.Room = room
+	return name, nil
+}
+
 type Message struct {
 	FirstPerson         *Character
 	SecondPerson        *Character
@@ -2,7 +2,10 @@ package world
 
 import (
 	"github.com/michaelvmata/path/items"
+	"github.com/michaelvmata/path/modifiers"
+	"github.com/michaelvmata/path/session"
 	"github.com/michaelvmata/path/stats"
+	"strings"
 	"testing"
 )
 
@@ -50,8 +53,12 @@ func TestPlayer(t *testing.T) {
 }
 
 type TestBuff struct {
-	Expired bool
-	name    string
+	Expired    bool
+	name       string
+	stacking   Stacking
+	stacks     int
+	refreshes  int
+	categories []Category
 }
 
 func (t *TestBuff) Update(tick int)        {}
@@ -64,6 +71,11 @@ func (t *TestBuff) AlreadyApplied() string { return t.name }
 func (t *TestBuff) Upkeep() int            { return 0 }
 func (t *TestBuff) Remaining() int         { return 0 }
 func (t *TestBuff) SetRemaining(int)       {}
+func (t *TestBuff) Stacking() Stacking     { return t.stacking }
+func (t *TestBuff) Stacks() int            { return t.stacks }
+func (t *TestBuff) Refresh()               { t.refreshes++ }
+func (t *TestBuff) Intensify()             { t.stacks++ }
+func (t *TestBuff) Categories() []Category { return t.categories }
 
 func TestPlayerBuff(t *testing.T) {
 	player := NewPlayer("Test UUID", "Test Handle")
@@ -87,10 +99,60 @@ func TestPlayerBuff(t *testing.T) {
 	player.Apply(buff)
 	buff2 := &TestBuff{Expired: false, name: "TestBuff2"}
 	player.Apply(buff2)
-	player.Unapply(buff.Name())
-	if len(player.Buffs) != 1 {
+	if err := player.Unapply(buff); err != nil || len(player.Buffs) != 1 {
 		t.Fatalf("Unapplied buff still applied")
 	}
+	if err := player.Unapply(buff); err == nil {
+		t.Fatalf("Unapplied a buff that wasn't applied")
+	}
+}
+
+func TestIndependentBuffs(t *testing.T) {
+	player := NewPlayer("Test UUID", "Test Handle")
+	stacking := Stacking{Rule: Independent}
+	first := &TestBuff{name: "curse", stacking: stacking}
+	second := &TestBuff{name: "curse", stacking: stacking}
+	third := &TestBuff{name: "curse", stacking: stacking}
+	player.Apply(first)
+	player.Apply(second)
+	player.Apply(third)
+	if len(player.Buffs) != 3 {
+		t.Fatalf("Independent buffs stacked %d", len(player.Buffs))
+	}
+
+	first.Expire()
+	player.Update(1)
+	if len(player.Buffs) != 2 || player.Buffs[0] != second {
+		t.Fatalf("Expired copy took its siblings with it")
+	}
+	if err := player.Unapply(third); err != nil || len(player.Buffs) != 1 || player.Buffs[0] != second {
+		t.Fatalf("Unapplied the wrong independent copy")
+	}
+}
+
+func TestBuffStacking(t *testing.T) {
+	player := NewPlayer("Test UUID", "Test Handle")
+	poison := &TestBuff{name: "poison", stacks: 1, stacking: Stacking{Rule: Intensify, Maximum: 2}, categories: []Category{Harmful, Physical}}
+	player.Apply(poison)
+	for i := 0; i < 2; i++ {
+		player.Apply(&TestBuff{name: "poison", stacking: poison.stacking})
+	}
+	if len(player.Buffs) != 1 || poison.stacks != 2 || poison.refreshes != 1 {
+		t.Fatalf("Poison stacks(%d) refreshes(%d) expected(2, 1)", poison.stacks, poison.refreshes)
+	}
+	ward := &TestBuff{name: "ward", stacking: Stacking{Rule: Independent}, categories: []Category{Beneficial, Magic}}
+	player.Apply(ward)
+	player.Apply(&TestBuff{name: "ward", stacking: Stacking{Rule: Independent}, categories: []Category{Beneficial, Magic}})
+	if len(player.Buffs) != 3 {
+		t.Fatalf("Independent buffs stacked")
+	}
+
+	if removed := player.Dispel(1, Beneficial, Magic); len(removed) != 1 || removed[0] != ward {
+		t.Fatalf("Dispel removed %v", removed)
+	}
+	if removed := player.Dispel(5, Harmful); len(removed) != 1 || removed[0] != poison || len(player.Buffs) != 1 {
+		t.Fatalf("Cleanse removed %v", removed)
+	}
 }
 
 func TestPlayerMemory(t *testing.T) {
@@ -227,3 +289,317 @@ func TestRoom(t *testing.T) {
 		t.Fatalf("Able to pickup item in room, twice")
 	}
 }
+
+func TestWorldSavePlayers(t *testing.T) {
+	w := NewWorld()
+	w.Players["Tester"] = NewPlayer("Test UUID", "Tester")
+	saved := 0
+	w.Saver = func(c *Character) { saved++ }
+	for i := 0; i < w.SaveTicks; i++ {
+		w.Update()
+	}
+	if saved != 1 {
+		t.Fatalf("Players saved(%d) expected(1)", saved)
+	}
+}
+
+func TestItemsSpawn(t *testing.T) {
+	w := NewWorld()
+	w.Items.AddPrototype(item.NewArmor("Helmet UUID", "test helmet", item.Head, []string{"helmet"}, ""))
+	first, ok := w.Items.Spawn("Helmet UUID")
+	if !ok {
+		t.Fatalf("Unable to spawn item")
+	}
+	second, _ := w.Items.Spawn("Helmet UUID")
+	if first == second || first.InstanceID() == second.InstanceID() {
+		t.Fatalf("Spawned items share an instance")
+	}
+	if len(w.Items.Instances) != 2 {
+		t.Fatalf("Instances(%d) expected(2)", len(w.Items.Instances))
+	}
+	w.Items.Unspawn(first)
+	if _, found := w.Items.Instances[first.InstanceID()]; found {
+		t.Fatalf("Unspawned item still an instance")
+	}
+	if _, ok := w.Items.Spawn("Missing UUID"); ok {
+		t.Fatalf("Spawned item without prototype")
+	}
+}
+
+func TestWorldResetArea(t *testing.T) {
+	w := NewWorld()
+	area := NewArea("Area UUID", "Test area")
+	area.ResetTicks = 5
+	w.Areas[area.UUID] = area
+	r := NewRoom("Room UUID", "Test room", "", 10, area)
+	area.Rooms[r.UUID] = r
+	w.Rooms[r.UUID] = r
+	w.Items.AddPrototype(item.NewArmor("Helmet UUID", "test helmet", item.Head, []string{"helmet"}, ""))
+	w.RoomItems[r.UUID] = append(w.RoomItems[r.UUID], NewRoomItem("Helmet UUID", 2))
+
+	w.SpawnItems()
+	if count := r.ItemCount("Helmet UUID"); count != 2 {
+		t.Fatalf("Room items(%d) expected(2)", count)
+	}
+	if _, err := r.PickupItem("helmet"); err != nil {
+		t.Fatalf("Unable to pickup item in room")
+	}
+	for i := 0; i < area.ResetTicks-1; i++ {
+		w.Update()
+	}
+	if count := r.ItemCount("Helmet UUID"); count != 1 {
+		t.Fatalf("Room reset early with items(%d)", count)
+	}
+	w.Update()
+	if count := r.ItemCount("Helmet UUID"); count != 2 {
+		t.Fatalf("Room items(%d) expected(2) after reset", count)
+	}
+}
+
+func TestExits(t *testing.T) {
+	exits := make(Exits)
+	exits[South] = &Exit{RoomUUID: "South UUID"}
+	exits["portal"] = &Exit{RoomUUID: "Portal UUID"}
+	exits[North] = &Exit{RoomUUID: "North UUID", Door: &Door{Name: "gate", Closed: true}}
+
+	names := exits.Names()
+	if strings.Join(names, ",") != "north,south,portal" {
+		t.Fatalf("Exit names %v", names)
+	}
+	if name, _, ok := exits.Find("po"); !ok || name != "portal" {
+		t.Fatalf("Unable to find portal by prefix")
+	}
+	if _, _, ok := exits.Find("up"); ok {
+		t.Fatalf("Found missing exit")
+	}
+	if name, _, ok := exits.FindDoor("gate"); !ok || name != North {
+		t.Fatalf("Unable to find door by name")
+	}
+	if exits.FirstExit() != "South UUID" {
+		t.Fatalf("First exit is through a closed door")
+	}
+}
+
+func TestDoor(t *testing.T) {
+	door := &Door{Name: "door", KeyUUID: "Key UUID"}
+	if err := door.Lock(); err != DoorOpen {
+		t.Fatalf("Locked an open door %v", err)
+	}
+	if err := door.Close(); err != nil {
+		t.Fatalf("Unable to close door %v", err)
+	}
+	if err := door.Lock(); err != nil {
+		t.Fatalf("Unable to lock door %v", err)
+	}
+	if err := door.Open(); err != DoorLocked {
+		t.Fatalf("Opened a locked door %v", err)
+	}
+	if err := door.Unlock(); err != nil {
+		t.Fatalf("Unable to unlock door %v", err)
+	}
+	if err := door.Open(); err != nil {
+		t.Fatalf("Unable to open door %v", err)
+	}
+	if err := (&Door{Name: "door", Closed: true}).Lock(); err != DoorHasNoLock {
+		t.Fatalf("Locked a door without a lock %v", err)
+	}
+}
+
+func TestWorldResetWhenEmpty(t *testing.T) {
+	w := NewWorld()
+	area := NewArea("Area UUID", "Test area")
+	area.ResetTicks = 2
+	area.ResetWhenEmpty = true
+	area.ResetMessage = "The area resets."
+	w.Areas[area.UUID] = area
+	r := NewRoom("Room UUID", "Test room", "", 10, area)
+	area.Rooms[r.UUID] = r
+	w.Rooms[r.UUID] = r
+
+	c := NewPlayer("Test UUID", "Tester")
+	c.Session = session.New()
+	r.Enter(c)
+	c.Room = r
+	for i := 0; i < area.ResetTicks; i++ {
+		w.Update()
+	}
+	if area.LastReset != 0 {
+		t.Fatalf("Area reset with a player present")
+	}
+	r.Exit(c)
+	w.Update()
+	if area.LastReset != w.Ticks {
+		t.Fatalf("Area not reset once empty")
+	}
+	w.Update()
+	if area.LastReset == w.Ticks {
+		t.Fatalf("Area reset again before its interval")
+	}
+}
+
+func TestWorldResetAfterDisconnect(t *testing.T) {
+	w := NewWorld()
+	area := NewArea("Area UUID", "Test area")
+	area.ResetTicks = 2
+	area.ResetWhenEmpty = true
+	w.Areas[area.UUID] = area
+	r := NewRoom("Room UUID", "Test room", "", 10, area)
+	area.Rooms[r.UUID] = r
+	w.Rooms[r.UUID] = r
+
+	c := NewPlayer("Test UUID", "Tester")
+	c.Session = session.New()
+	r.Enter(c)
+	c.Room = r
+	w.Ticks = area.ResetTicks
+	if w.IsResetDue(area) {
+		t.Fatalf("Reset due with a connected player present")
+	}
+	c.Session = nil
+	if !w.IsResetDue(area) {
+		t.Fatalf("Disconnected player held back the reset")
+	}
+}
+
+func TestWorldResetMessage(t *testing.T) {
+	w := NewWorld()
+	area := NewArea("Area UUID", "Test area")
+	area.ResetMessage = "The area resets."
+	r := NewRoom("Room UUID", "Test room", "", 10, area)
+	area.Rooms[r.UUID] = r
+	c := NewPlayer("Test UUID", "Tester")
+	c.Session = session.New()
+	r.Enter(c)
+	w.ResetArea(area)
+	if message := <-c.Session.Outgoing; !strings.Contains(message, area.ResetMessage) {
+		t.Fatalf("Reset message not shown %q", message)
+	}
+}
+
+func TestResistanceModifiers(t *testing.T) {
+	c := NewPlayer("Test UUID", "Tester")
+	jerkin := item.NewArmor("Jerkin UUID", "test jerkin", item.Body, []string{"jerkin"}, "")
+	jerkin.AddModifier(modifiers.SlashResistance, 10)
+	jerkin.AddModifier(modifiers.PierceVulnerability, 5)
+	c.Gear.Equip(jerkin)
+	c.CalculateModifiers()
+	if c.Resistances.Of(item.Slash) != 10 || c.Resistances.Of(item.Pierce) != -5 {
+		t.Fatalf("Resistances slash(%d) pierce(%d)", c.Resistances.Of(item.Slash), c.Resistances.Of(item.Pierce))
+	}
+	c.Gear.Remove("jerkin")
+	c.CalculateModifiers()
+	if c.Resistances.Of(item.Slash) != 0 {
+		t.Fatalf("Resistance remains after removing armor")
+	}
+}
+
+func TestGroup(t *testing.T) {
+	room := NewRoom("Room UUID", "Test Room", "", 10, nil)
+	elsewhere := NewRoom("Elsewhere UUID", "Other Room", "", 10, nil)
+	leader := NewPlayer("Leader UUID", "Leader")
+	member := NewPlayer("Member UUID", "Member")
+	away := NewPlayer("Away UUID", "Away")
+	enemy := NewPlayer("Enemy UUID", "Enemy")
+	for _, c := range []*Character{leader, member, enemy} {
+		room.Enter(c)
+		c.Room = room
+	}
+	elsewhere.Enter(away)
+	away.Room = elsewhere
+
+	group := NewGroup(leader)
+	group.Add(member)
+	group.Add(away)
+	if err := group.Add(member); err != AlreadyGrouped {
+		t.Fatalf("Member added twice %v", err)
+	}
+	if present := member.GroupInRoom(); len(present) != 2 {
+		t.Fatalf("Group in room expected(2) actual(%d)", len(present))
+	}
+	if present := enemy.GroupInRoom(); len(present) != 1 || present[0] != enemy {
+		t.Fatalf("Ungrouped character not on their own")
+	}
+
+	enemy.StartAttacking(leader)
+	leader.StartAttacking(enemy)
+	member.AutoAssist()
+	away.AutoAssist()
+	if !member.IsAttacking(enemy) || !enemy.IsAttacking(member) {
+		t.Fatalf("Member didn't assist the leader")
+	}
+	if away.IsFighting() {
+		t.Fatalf("Member assisted from another room")
+	}
+
+	group.Remove(away)
+	if away.Group != nil || group.Find("away") != nil {
+		t.Fatalf("Member not removed")
+	}
+	group.Remove(leader)
+	if member.Group != nil || leader.Group != nil {
+		t.Fatalf("Group not disbanded when the leader left")
+	}
+}
+
+func TestThreat(t *testing.T) {
+	mobile := NewPlayer("Mobile UUID", "Mobile")
+	mobile.IsPlayer = false
+	tank := NewPlayer("Tank UUID", "Tank")
+	striker := NewPlayer("Striker UUID", "Striker")
+	mobile.StartAttacking(tank)
+	mobile.StartAttacking(striker)
+
+	mobile.ChooseTarget()
+	if mobile.ImmediateDefender() != tank {
+		t.Fatalf("Mobile switched targets without threat")
+	}
+	mobile.Threat.Add(tank, 10)
+	mobile.Threat.Add(striker, 20)
+	mobile.ChooseTarget()
+	if mobile.ImmediateDefender() != striker {
+		t.Fatalf("Mobile didn't turn on the highest threat")
+	}
+	mobile.Threat.Taunt(tank)
+	if mobile.Threat[tank] != 21 {
+		t.Fatalf("Taunt threat(%d) expected(21)", mobile.Threat[tank])
+	}
+	mobile.ChooseTarget()
+	if mobile.ImmediateDefender() != tank || len(mobile.Attacking) != 2 {
+		t.Fatalf("Mobile didn't turn on the taunting tank")
+	}
+	mobile.Threat.Remove(tank)
+	if target := mobile.Threat.Highest([]*Character{tank, striker}); target != striker {
+		t.Fatalf("Removed threat still counted")
+	}
+}
+
+func TestControls(t *testing.T) {
+	c := NewPlayer("Test UUID", "Test Handle")
+	expected := []int{4, 2, 1, 0}
+	for i, duration := range expected {
+		if applied := c.Control(Stun, 4); applied != duration {
+			t.Fatalf("Stun %d lasted(%d) expected(%d)", i, applied, duration)
+		}
+	}
+	if !c.IsStunned() || c.IsRooted() || c.Controls[Stun].Remaining != 4 {
+		t.Fatalf("Stun not in effect")
+	}
+	if c.Control(Root, 2) != 2 || !c.IsRooted() {
+		t.Fatalf("Root diminished by stuns")
+	}
+	for i := 0; i < 4; i++ {
+		c.Controls.Update()
+	}
+	if c.IsStunned() || c.IsRooted() {
+		t.Fatalf("Controls didn't wear off")
+	}
+	if c.Control(Stun, 4) != 0 {
+		t.Fatalf("Stun landed during diminishing window")
+	}
+	for i := 0; i < DiminishingWindow; i++ {
+		c.Controls.Update()
+	}
+	if len(c.Controls) != 0 || c.Control(Stun, 4) != 4 {
+		t.Fatalf("Diminishing returns didn't reset")
+	}
+}